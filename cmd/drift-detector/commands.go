@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/internal/appconfig"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/notifier"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/reporter"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+func runDetect(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("detect", stderr)
+	planFile := fs.String("plan", "", "Compare against a `terraform show -json` plan instead of the state")
+	sourceDir := fs.String("source-dir", "", "Terraform configuration directory, used to locate resources in SARIF output")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+
+	var parser terraform.Parser
+	source := *planFile
+	if *planFile != "" {
+		parser = terraform.NewPlanParser(*planFile)
+	} else {
+		parser, source, err = newStateParser(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	instanceIDs := cfg.InstanceIDs
+	if len(instanceIDs) == 0 {
+		instanceIDs, err = parser.GetInstanceIDs()
+		if err != nil {
+			return fmt.Errorf("failed to list instances in %s: %w", source, err)
+		}
+	}
+
+	ec2Client, err := newEC2Client(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	d := detector.New(ec2Client, parser, cfg.Attributes,
+		detector.WithSensitiveAttributes(cfg.SensitiveAttributes...))
+
+	var results []detector.Result
+	if cfg.Concurrent {
+		results, err = d.DetectConcurrent(ctx, instanceIDs)
+	} else {
+		results, err = d.DetectBatch(ctx, instanceIDs)
+	}
+	if err != nil {
+		return fmt.Errorf("drift detection failed: %w", err)
+	}
+
+	meta := reporter.Metadata{
+		GeneratedAt: time.Now(),
+		StateFile:   source,
+		Attributes:  cfg.Attributes,
+		Duration:    time.Since(started),
+	}
+
+	var sources terraform.SourceIndex
+	if *sourceDir != "" {
+		sources, err = terraform.LoadSources(*sourceDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := report(ctx, cfg, meta, sources, results, stdout); err != nil {
+		return err
+	}
+	return finish(cfg, results, notify(ctx, cfg, meta, results), stderr)
+}
+
+func runDiscover(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("discover", stderr)
+	var tags, vpcs, states listFlag
+	fs.Var(&tags, "tag", "Only consider instances with this tag, as key=value (repeatable)")
+	fs.Var(&vpcs, "vpc", "Only consider instances in this VPC (repeatable)")
+	fs.Var(&states, "state", "Only consider instances in this state, e.g. running (repeatable; default excludes terminated and shutting-down)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: drift-detector discover [flags] [additional state files...]")
+		fs.PrintDefaults()
+	}
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	filter := aws.InstanceFilter{VpcIDs: vpcs, States: states}
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			fmt.Fprintf(stderr, "invalid --tag %q: use key=value\n", tag)
+			return errUsage
+		}
+		if filter.Tags == nil {
+			filter.Tags = make(map[string]string)
+		}
+		filter.Tags[key] = value
+	}
+
+	parser, source, err := newStateParser(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	statePaths := []string{source}
+	parsers := []terraform.Parser{parser}
+	for _, path := range fs.Args() {
+		statePaths = append(statePaths, path)
+		parsers = append(parsers, terraform.NewStateParser(path, stateParserOptions(cfg)...))
+	}
+
+	ec2Client, err := newEC2Client(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	results, err := detector.Discover(ctx, ec2Client, parsers, filter,
+		detector.WithSensitiveAttributes(cfg.SensitiveAttributes...))
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	meta := reporter.Metadata{
+		GeneratedAt: time.Now(),
+		StateFile:   strings.Join(statePaths, ","),
+		Attributes:  cfg.Attributes,
+	}
+	if err := report(ctx, cfg, meta, nil, results, stdout); err != nil {
+		return err
+	}
+	return finish(cfg, results, notify(ctx, cfg, meta, results), stderr)
+}
+
+func runListInstances(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("list-instances", stderr)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	parser, source, err := newStateParser(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	ids, err := parser.GetInstanceIDs()
+	if err != nil {
+		return fmt.Errorf("failed to list instances in %s: %w", source, err)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE ID\tADDRESS")
+	for _, id := range ids {
+		address, err := parser.GetInstanceAddress(id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\n", id, address)
+	}
+	return w.Flush()
+}
+
+func runExplain(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	// explain takes attribute names only, none of the configuration flags
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: drift-detector explain [attribute...]")
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	registry := detector.NewDefaultRegistry()
+	names := fs.Args()
+	if len(names) == 0 {
+		names = registry.Names()
+	}
+
+	for i, name := range names {
+		schema, ok := registry.Lookup(name)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown attribute %q: run \"drift-detector explain\" to list attributes\n", name)
+			return errUsage
+		}
+
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "%s\n", schema.Name)
+		fmt.Fprintf(stdout, "  Type:        %s\n", schema.Type)
+		fmt.Fprintf(stdout, "  Severity:    %s\n", schemaSeverity(schema))
+		if schema.Sensitive {
+			fmt.Fprintln(stdout, "  Sensitive:   yes")
+		}
+		fmt.Fprintf(stdout, "  Description: %s\n", schema.Description)
+	}
+	return nil
+}
+
+func runVersion(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fmt.Fprintf(stdout, "drift-detector %s\n", version)
+	return nil
+}
+
+// report writes results in the configured format
+func report(ctx context.Context, cfg appconfig.Config, meta reporter.Metadata, sources terraform.SourceIndex, results []detector.Result, stdout io.Writer) error {
+	w, closeOutput, err := openOutput(cfg, stdout)
+	if err != nil {
+		return err
+	}
+
+	rep, err := newReporter(cfg, meta, sources, w)
+	if err != nil {
+		closeOutput()
+		return err
+	}
+
+	// Textfile and Pushgateway metrics can accompany any report format
+	if cfg.OutputFormat != "prometheus" && (cfg.PrometheusTextfile != "" || cfg.PushgatewayURL != "") {
+		metrics := reporter.NewPrometheusReporter(nil, meta)
+		metrics.TextfilePath = cfg.PrometheusTextfile
+		metrics.PushgatewayURL = cfg.PushgatewayURL
+		metrics.UnsafeShowSensitive = cfg.UnsafeShowSensitive
+		rep = reporter.NewMultiReporter(rep, metrics)
+	}
+
+	if err := reporter.ReportContext(ctx, rep, results); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
+
+func newReporter(cfg appconfig.Config, meta reporter.Metadata, sources terraform.SourceIndex, w io.Writer) (reporter.Reporter, error) {
+	unsafe := cfg.UnsafeShowSensitive
+
+	switch cfg.OutputFormat {
+	case "console":
+		rep := reporter.NewConsoleReporter(w)
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "json":
+		rep := reporter.NewJSONReporter(w, meta)
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "sarif":
+		rep := reporter.NewSARIFReporter(w, meta)
+		rep.Sources = sources
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "junit":
+		rep := reporter.NewJUnitReporter(w, meta)
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "markdown":
+		rep := reporter.NewMarkdownReporter(w, meta)
+		rep.MaxBytes = reporter.GitHubCommentLimit
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "html":
+		rep := reporter.NewHTMLReporter(w, meta)
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	case "prometheus":
+		rep := reporter.NewPrometheusReporter(w, meta)
+		rep.TextfilePath = cfg.PrometheusTextfile
+		rep.PushgatewayURL = cfg.PushgatewayURL
+		rep.UnsafeShowSensitive = unsafe
+		return rep, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", cfg.OutputFormat)
+	}
+}
+
+// notify sends the results to every configured notifier, continuing past
+// failures
+func notify(ctx context.Context, cfg appconfig.Config, meta reporter.Metadata, results []detector.Result) error {
+	var notifiers []notifier.Notifier
+
+	if cfg.SlackWebhookURL != "" {
+		slack := notifier.NewSlackNotifier(cfg.SlackWebhookURL)
+		slack.UnsafeShowSensitive = cfg.UnsafeShowSensitive
+		notifiers = append(notifiers, slack)
+	}
+	if cfg.WebhookURL != "" {
+		webhook := notifier.NewWebhookNotifier(cfg.WebhookURL, []byte(cfg.WebhookSecret))
+		webhook.Metadata = meta
+		webhook.UnsafeShowSensitive = cfg.UnsafeShowSensitive
+		notifiers = append(notifiers, webhook)
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		pagerDuty := notifier.NewPagerDutyNotifier(cfg.PagerDutyRoutingKey)
+		pagerDuty.Attributes = cfg.Attributes
+		pagerDuty.StateFile = cfg.PagerDutyStateFile
+		pagerDuty.UnsafeShowSensitive = cfg.UnsafeShowSensitive
+		notifiers = append(notifiers, pagerDuty)
+	}
+
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("notification failed: %w", err)
+	}
+	return nil
+}
+
+func schemaSeverity(schema detector.AttributeSchema) detector.Severity {
+	if schema.Severity == "" {
+		return detector.SeverityMedium
+	}
+	return schema.Severity
+}
+
+// listFlag collects a repeatable string flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+
+	"github.com/sanjaesan/ec2-drift-detector/internal/appconfig"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+// Exit codes are part of the CLI contract; see "Exit Codes" in the README
+const (
+	exitOK    = 0 // No result matched the --fail-on policy
+	exitDrift = 1 // Drift matched the --fail-on policy
+	exitUsage = 2 // Invalid command, flags or configuration
+	exitError = 3 // Errors occurred, in the run or in instance results
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// lookupEnv reads DRIFT_DETECTOR_* variables; tests replace it
+var lookupEnv = os.LookupEnv
+
+// command is a drift-detector subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout, stderr io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"detect", "Compare instances against Terraform state (default)", runDetect},
+		{"discover", "List instances in AWS that no Terraform state manages", runDiscover},
+		{"list-instances", "List the instances in the Terraform state", runListInstances},
+		{"explain", "Describe the attributes that can be checked", runExplain},
+		{"version", "Print the version", runVersion},
+	}
+}
+
+var (
+	// errUsage marks errors caused by invalid arguments; the message has
+	// already been printed
+	errUsage = errors.New("invalid usage")
+
+	// errDrift and errResultErrors report that the --fail-on policy matched
+	// after the report was written
+	errDrift        = errors.New("drift detected")
+	errResultErrors = errors.New("instances could not be checked")
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to a subcommand and returns the process exit code.
+// Without a subcommand name, flags are passed to detect.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	name := "detect"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		usage(stdout)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		return exitCode(cmd.run(ctx, args, stdout, stderr), stderr)
+	}
+
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", name)
+	usage(stderr)
+	return exitUsage
+}
+
+// exitCode maps a command's error to the process exit code
+func exitCode(err error, stderr io.Writer) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errDrift):
+		return exitDrift
+	case errors.Is(err, errResultErrors):
+		return exitError
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: drift-detector <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "drift-detector <command> -h" for the flags of a command.`)
+}
+
+// newFlagSet creates a flag set for a subcommand with the shared
+// configuration flags registered
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	appconfig.RegisterFlags(fs)
+	return fs
+}
+
+// loadConfig parses flags and resolves the configuration
+func loadConfig(fs *flag.FlagSet, args []string) (appconfig.Config, error) {
+	if err := fs.Parse(args); err != nil {
+		return appconfig.Config{}, errUsage
+	}
+
+	cfg, err := appconfig.Load(appconfig.LoadOptions{Flags: fs, LookupEnv: lookupEnv})
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return appconfig.Config{}, errUsage
+	}
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(fs.Output(), "Warning: %s\n", warning)
+	}
+	return cfg, nil
+}
+
+// newEC2Client returns the mock client or one for the configured AWS
+// account and region
+func newEC2Client(ctx context.Context, cfg appconfig.Config) (aws.EC2Client, error) {
+	if cfg.UseMockData {
+		return aws.NewMockEC2Client(), nil
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return aws.NewAWSEC2Client(ec2.NewFromConfig(awsCfg)), nil
+}
+
+// stateParserOptions returns the parser options selected by the config
+func stateParserOptions(cfg appconfig.Config) []terraform.StateParserOption {
+	if cfg.StreamState {
+		return []terraform.StateParserOption{terraform.WithStreaming()}
+	}
+	return nil
+}
+
+// newStateParser returns a parser for the configured S3 backend or state
+// file, along with a description of the source
+func newStateParser(ctx context.Context, cfg appconfig.Config) (*terraform.StateParser, string, error) {
+	if cfg.StateBucket == "" {
+		return terraform.NewStateParser(cfg.TerraformStateFile, stateParserOptions(cfg)...), cfg.TerraformStateFile, nil
+	}
+
+	backend := terraform.S3Backend{
+		Bucket:    cfg.StateBucket,
+		Key:       cfg.StateKey,
+		Region:    cfg.StateRegion,
+		Workspace: cfg.StateWorkspace,
+	}
+	if backend.Region == "" {
+		backend.Region = cfg.Region
+	}
+
+	client, err := terraform.NewS3Client(ctx, backend)
+	if err != nil {
+		return nil, "", err
+	}
+	parser, err := terraform.NewS3StateParser(ctx, client, backend, stateParserOptions(cfg)...)
+	if err != nil {
+		return nil, "", err
+	}
+	return parser, parser.Source(), nil
+}
+
+// applyPolicy evaluates the --fail-on policy against written results.
+// Errors take precedence over drift, since drift may be hidden behind them.
+func applyPolicy(cfg appconfig.Config, results []detector.Result) error {
+	policy, err := detector.ParsePolicy(cfg.FailOn)
+	if err != nil {
+		return err
+	}
+
+	verdict := policy.Evaluate(results)
+	switch {
+	case verdict.Errors:
+		return errResultErrors
+	case verdict.Drift:
+		return errDrift
+	default:
+		return nil
+	}
+}
+
+// finish applies the --fail-on policy once notifications have been sent.
+// A failed notification is only the outcome when the policy passes, so
+// drift still exits with its own code; the notification error is printed.
+func finish(cfg appconfig.Config, results []detector.Result, notifyErr error, stderr io.Writer) error {
+	policyErr := applyPolicy(cfg, results)
+	if notifyErr == nil {
+		return policyErr
+	}
+	if policyErr == nil {
+		return notifyErr
+	}
+
+	fmt.Fprintf(stderr, "Error: %v\n", notifyErr)
+	return policyErr
+}
+
+// openOutput returns the report destination and a function closing it
+func openOutput(cfg appconfig.Config, stdout io.Writer) (io.Writer, func() error, error) {
+	if cfg.OutputFile == "" {
+		return stdout, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(cfg.OutputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testState = "../../testdata/terraform.tfstate"
+
+// TestMain keeps DRIFT_DETECTOR_* variables in the environment from
+// changing the tests
+func TestMain(m *testing.M) {
+	lookupEnv = func(string) (string, bool) { return "", false }
+	os.Exit(m.Run())
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   []string
+		stderr   string
+	}{
+		{
+			name:     "detect defaults to every instance in state",
+			args:     []string{"--terraform-state", testState, "--mock"},
+			exitCode: 1,
+			stdout:   []string{"i-1234567890abcdef0", "i-0987654321fedcba0", "i-0fedcba9876543210", "Total Instances Checked:     3"},
+		},
+		{
+			name:     "detect selected instances",
+			args:     []string{"detect", "--terraform-state", testState, "--mock", "--instances", "i-0987654321fedcba0", "--attributes", "instance_type"},
+			exitCode: 0,
+			stdout:   []string{"Drift Detected: NO", "Total Instances Checked:     1"},
+		},
+		{
+			name:     "detect with a streamed state",
+			args:     []string{"--terraform-state", testState, "--mock", "--stream-state"},
+			exitCode: 1,
+			stdout:   []string{"aws_instance.batch", "Total Instances Checked:     3"},
+		},
+		{
+			name:     "discover",
+			args:     []string{"discover", "--terraform-state", testState, "--mock", "--tag", "Owner=ops"},
+			exitCode: 1,
+			stdout:   []string{"i-0a1b2c3d4e5f67890", "Status: UNMANAGED"},
+		},
+		{
+			name:     "fail on critical drift only",
+			args:     []string{"--terraform-state", testState, "--mock", "--instances", "i-1234567890abcdef0,i-0987654321fedcba0", "--fail-on", "severity>=critical"},
+			exitCode: 1,
+			stdout:   []string{"vpc_security_group_ids"},
+		},
+		{
+			name:     "fail on critical drift ignores lower severities",
+			args:     []string{"--terraform-state", testState, "--mock", "--instances", "i-1234567890abcdef0", "--fail-on", "severity>=critical"},
+			exitCode: 0,
+			stdout:   []string{"instance_type"},
+		},
+		{
+			name:     "fail on severity counts unmanaged instances",
+			args:     []string{"discover", "--terraform-state", testState, "--mock", "--tag", "Owner=ops", "--fail-on", "severity>=high"},
+			exitCode: 1,
+			stdout:   []string{"Status: UNMANAGED"},
+		},
+		{
+			name:     "fail on none",
+			args:     []string{"--terraform-state", testState, "--mock", "--fail-on", "none"},
+			exitCode: 0,
+			stdout:   []string{"Drift Detected: YES"},
+		},
+		{
+			name:     "instances that cannot be checked",
+			args:     []string{"--terraform-state", testState, "--mock", "--instances", "i-00000000000000000"},
+			exitCode: 3,
+			stdout:   []string{"i-00000000000000000"},
+		},
+		{
+			name:     "runtime error",
+			args:     []string{"--terraform-state", "missing.tfstate", "--mock"},
+			exitCode: 3,
+			stderr:   "Error:",
+		},
+		{
+			name:     "invalid fail-on rule",
+			args:     []string{"--fail-on", "sometimes"},
+			exitCode: 2,
+			stderr:   `unknown fail-on rule "sometimes"`,
+		},
+		{
+			name:     "list instances",
+			args:     []string{"list-instances", "--terraform-state", testState},
+			exitCode: 0,
+			stdout:   []string{"i-0fedcba9876543210  aws_instance.batch"},
+		},
+		{
+			name:     "explain",
+			args:     []string{"explain", "iam_instance_profile"},
+			exitCode: 0,
+			stdout:   []string{"iam_instance_profile", "Severity:    critical"},
+		},
+		{
+			name:     "unregistered attribute is compared with a warning",
+			args:     []string{"--terraform-state", testState, "--mock", "--instances", "i-0987654321fedcba0", "--attributes", "instance_type,key_name,ebs_optimized"},
+			exitCode: 0,
+			stdout:   []string{"Drift Detected: NO"},
+			stderr:   `Warning: attribute "ebs_optimized" has no schema`,
+		},
+		{
+			name:     "explain rejects configuration flags",
+			args:     []string{"explain", "--mock"},
+			exitCode: 2,
+			stderr:   "flag provided but not defined: -mock",
+		},
+		{
+			name:     "explain unknown attribute",
+			args:     []string{"explain", "colour"},
+			exitCode: 2,
+			stderr:   `unknown attribute "colour"`,
+		},
+		{
+			name:     "version",
+			args:     []string{"version"},
+			exitCode: 0,
+			stdout:   []string{"drift-detector dev"},
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"destroy"},
+			exitCode: 2,
+			stderr:   `Unknown command "destroy"`,
+		},
+		{
+			name:     "invalid configuration",
+			args:     []string{"--format", "xml"},
+			exitCode: 2,
+			stderr:   `output format "xml" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(context.Background(), tt.args, &stdout, &stderr)
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.exitCode, code, stderr.String())
+			}
+			for _, want := range tt.stdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("Expected stdout to contain %q, got:\n%s", want, stdout.String())
+				}
+			}
+			if tt.stderr != "" && !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got:\n%s", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_JSONReport(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	run(context.Background(), []string{"--terraform-state", testState, "--mock", "--format", "json"}, &stdout, &stderr)
+
+	var report struct {
+		Metadata struct {
+			StateFile string `json:"state_file"`
+		} `json:"metadata"`
+		Results []struct {
+			Address string `json:"address"`
+			Status  string `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v (stderr: %s)", err, stderr.String())
+	}
+
+	if report.Metadata.StateFile != testState || len(report.Results) != 3 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if report.Results[2].Address != "aws_instance.batch" || report.Results[2].Status != "deleted_outside_terraform" {
+		t.Errorf("Expected aws_instance.batch to be deleted, got %+v", report.Results[2])
+	}
+}
+
+func TestRun_Integrations(t *testing.T) {
+	var notified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified++
+	}))
+	defer server.Close()
+
+	textfile := filepath.Join(t.TempDir(), "drift.prom")
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), []string{
+		"--terraform-state", testState, "--mock",
+		"--prometheus-textfile", textfile,
+		"--slack-webhook-url", server.URL,
+	}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+
+	if !strings.Contains(stdout.String(), "Drift Detected: YES") {
+		t.Errorf("Expected the console report on stdout, got:\n%s", stdout.String())
+	}
+	metrics, err := os.ReadFile(textfile)
+	if err != nil {
+		t.Fatalf("Expected the Prometheus textfile to be written, got %v", err)
+	}
+	if !strings.Contains(string(metrics), "ec2_drift_instances_checked 3") {
+		t.Errorf("Expected drift metrics in the textfile, got:\n%s", metrics)
+	}
+	if notified != 1 {
+		t.Errorf("Expected 1 Slack notification, got %d", notified)
+	}
+}
+
+func TestRun_NotificationFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		failOn   string
+		exitCode int
+	}{
+		{"drift keeps its exit code", "drift,error", 1},
+		{"error when the policy passes", "none", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(context.Background(), []string{"--terraform-state", testState, "--mock", "--webhook-url", server.URL, "--fail-on", tt.failOn}, &stdout, &stderr)
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+			if !strings.Contains(stderr.String(), "notification failed") {
+				t.Errorf("Expected the notification error on stderr, got:\n%s", stderr.String())
+			}
+		})
+	}
+}
@@ -1,11 +1,144 @@
 package appconfig
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
 // Config holds application configuration
 type Config struct {
-	TerraformStateFile string
-	InstanceIDs        []string
-	Attributes         []string
-	UseMockData        bool
-	Concurrent         bool
-	OutputFormat       string
-}
\ No newline at end of file
+	TerraformStateFile  string
+	InstanceIDs         []string
+	Attributes          []string
+	UseMockData         bool
+	Concurrent          bool
+	OutputFormat        string
+	OutputFile          string // Empty writes the report to stdout
+	Region              string
+	SensitiveAttributes []string
+	UnsafeShowSensitive bool
+	FailOn              string // Policy rules, see detector.ParsePolicy
+
+	// StateBucket reads the state from an S3 backend instead of
+	// TerraformStateFile
+	StateBucket    string
+	StateKey       string
+	StateRegion    string // Defaults to Region
+	StateWorkspace string
+
+	// StreamState decodes state files and S3 state token by token, keeping
+	// only aws_instance resources in memory
+	StreamState bool
+
+	PrometheusTextfile string // node_exporter textfile collector file
+	PushgatewayURL     string
+
+	SlackWebhookURL     string
+	WebhookURL          string
+	WebhookSecret       string
+	PagerDutyRoutingKey string
+	PagerDutyStateFile  string // Open incidents, see notifier.PagerDutyNotifier
+}
+
+// OutputFormats lists the supported values of OutputFormat
+var OutputFormats = []string{"console", "json", "sarif", "junit", "markdown", "html", "prometheus"}
+
+var instanceIDPattern = regexp.MustCompile(`^i-[0-9a-f]{8}([0-9a-f]{9})?$`)
+
+// attributeNamePattern matches Terraform attribute names. Only the
+// top-level name is checked; nested paths such as tags.Env are free-form.
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Default returns the configuration used when nothing else is set
+func Default() Config {
+	return Config{
+		TerraformStateFile: "terraform.tfstate",
+		Attributes:         []string{"instance_type", "ami", "subnet_id", "vpc_security_group_ids", "tags"},
+		OutputFormat:       "console",
+		FailOn:             detector.DefaultPolicy,
+	}
+}
+
+// Validate checks the configuration and returns every problem found, each
+// saying how to fix it
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.TerraformStateFile == "" {
+		errs = append(errs, errors.New("no Terraform state file set: pass --terraform-state, set DRIFT_DETECTOR_STATE_FILE or add state_file to the config file"))
+	}
+
+	for _, id := range c.InstanceIDs {
+		if !instanceIDPattern.MatchString(id) {
+			errs = append(errs, fmt.Errorf("instance ID %q is invalid: EC2 instance IDs look like i-0123456789abcdef0", id))
+		}
+	}
+
+	if len(c.Attributes) == 0 {
+		errs = append(errs, errors.New("no attributes to check: list at least one, e.g. --attributes=instance_type,ami"))
+	}
+	hasEmpty := false
+	for _, attr := range c.Attributes {
+		if strings.TrimSpace(attr) == "" {
+			hasEmpty = true
+			continue
+		}
+		name, _, _ := strings.Cut(attr, ".")
+		if !attributeNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("attribute %q is not a Terraform attribute name: use snake_case, e.g. instance_type", attr))
+		}
+	}
+	if hasEmpty {
+		errs = append(errs, errors.New("attribute list contains an empty name: remove the extra comma"))
+	}
+
+	if !contains(OutputFormats, c.OutputFormat) {
+		errs = append(errs, fmt.Errorf("output format %q is not supported: use one of %s", c.OutputFormat, strings.Join(OutputFormats, ", ")))
+	}
+
+	if (c.StateBucket == "") != (c.StateKey == "") {
+		errs = append(errs, errors.New("S3 state needs both a bucket and a key: pass --state-bucket and --state-key together"))
+	}
+
+	if c.PagerDutyStateFile != "" && c.PagerDutyRoutingKey == "" {
+		errs = append(errs, errors.New("a PagerDuty state file is set without a routing key: pass --pagerduty-routing-key"))
+	}
+
+	if _, err := detector.ParsePolicy(c.FailOn); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Warnings describes settings that are valid but probably not intended.
+// Attributes missing from the registry are still compared, with the
+// generic comparator, so they are reported here rather than by Validate.
+func (c Config) Warnings() []string {
+	registry := detector.NewDefaultRegistry()
+	warnings := make([]string, 0)
+
+	for _, attr := range c.Attributes {
+		name, _, _ := strings.Cut(attr, ".")
+		if !attributeNamePattern.MatchString(name) {
+			continue
+		}
+		if _, ok := registry.Lookup(name); !ok {
+			warnings = append(warnings, fmt.Sprintf("attribute %q has no schema and is compared as-is: run \"drift-detector explain\" to list known attributes", attr))
+		}
+	}
+	return warnings
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
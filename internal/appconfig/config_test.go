@@ -0,0 +1,213 @@
+package appconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const configYAML = `
+state_file: shared.tfstate
+attributes: [instance_type, ami]
+format: json
+
+profiles:
+  prod:
+    state_file: prod.tfstate
+    instances: [i-0123456789abcdef0]
+    concurrent: true
+  staging:
+    state_file: staging.tfstate
+`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func parseFlags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	return fs
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeConfig(t, "drift.yaml", configYAML)
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		expected func(c *Config)
+	}{
+		{
+			name: "defaults only",
+			expected: func(c *Config) {
+				*c = Default()
+			},
+		},
+		{
+			name: "file overrides defaults",
+			env:  map[string]string{"DRIFT_DETECTOR_CONFIG": file},
+			expected: func(c *Config) {
+				*c = Default()
+				c.TerraformStateFile = "shared.tfstate"
+				c.Attributes = []string{"instance_type", "ami"}
+				c.OutputFormat = "json"
+			},
+		},
+		{
+			name: "profile overrides shared settings",
+			args: []string{"--config", file, "--profile", "prod"},
+			expected: func(c *Config) {
+				*c = Default()
+				c.TerraformStateFile = "prod.tfstate"
+				c.InstanceIDs = []string{"i-0123456789abcdef0"}
+				c.Attributes = []string{"instance_type", "ami"}
+				c.OutputFormat = "json"
+				c.Concurrent = true
+			},
+		},
+		{
+			name: "env overrides file",
+			env:  map[string]string{"DRIFT_DETECTOR_PROFILE": "staging", "DRIFT_DETECTOR_STATE_FILE": "env.tfstate", "DRIFT_DETECTOR_MOCK": "true"},
+			args: []string{"--config", file},
+			expected: func(c *Config) {
+				*c = Default()
+				c.TerraformStateFile = "env.tfstate"
+				c.Attributes = []string{"instance_type", "ami"}
+				c.OutputFormat = "json"
+				c.UseMockData = true
+			},
+		},
+		{
+			name: "S3 state and integrations",
+			env:  map[string]string{"DRIFT_DETECTOR_SLACK_WEBHOOK_URL": "https://hooks.example/T1", "DRIFT_DETECTOR_PAGERDUTY_ROUTING_KEY": "key"},
+			args: []string{"--state-bucket", "tf-state", "--state-key", "prod.tfstate", "--stream-state", "--prometheus-textfile", "/var/lib/node_exporter/drift.prom"},
+			expected: func(c *Config) {
+				*c = Default()
+				c.StateBucket = "tf-state"
+				c.StateKey = "prod.tfstate"
+				c.StreamState = true
+				c.PrometheusTextfile = "/var/lib/node_exporter/drift.prom"
+				c.SlackWebhookURL = "https://hooks.example/T1"
+				c.PagerDutyRoutingKey = "key"
+			},
+		},
+		{
+			name: "flag overrides env",
+			env:  map[string]string{"DRIFT_DETECTOR_FORMAT": "sarif", "DRIFT_DETECTOR_ATTRIBUTES": "tags"},
+			args: []string{"--format", "junit", "--attributes", "ami, tags"},
+			expected: func(c *Config) {
+				*c = Default()
+				c.OutputFormat = "junit"
+				c.Attributes = []string{"ami", "tags"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(LoadOptions{LookupEnv: env(tt.env), Flags: parseFlags(t, tt.args...)})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var expected Config
+			tt.expected(&expected)
+			if !reflect.DeepEqual(cfg, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, cfg)
+			}
+		})
+	}
+}
+
+func TestLoad_JSONFile(t *testing.T) {
+	file := writeConfig(t, "drift.json", `{"format": "markdown", "profiles": {"prod": {"region": "eu-west-1"}}}`)
+
+	cfg, err := Load(LoadOptions{File: file, Profile: "prod", LookupEnv: env(nil)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.OutputFormat != "markdown" || cfg.Region != "eu-west-1" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	yamlFile := writeConfig(t, "drift.yaml", configYAML)
+	typo := writeConfig(t, "typo.yaml", "stat_file: x.tfstate\n")
+
+	tests := []struct {
+		name    string
+		opts    LoadOptions
+		args    []string
+		message string
+	}{
+		{"unknown profile", LoadOptions{File: yamlFile, Profile: "prd"}, nil, `profile "prd" not found`},
+		{"lists profiles", LoadOptions{File: yamlFile, Profile: "prd"}, nil, "available profiles are prod, staging"},
+		{"profile without file", LoadOptions{Profile: "prod"}, nil, "no config file given"},
+		{"unknown key", LoadOptions{File: typo}, nil, "field stat_file not found"},
+		{"bad boolean", LoadOptions{LookupEnv: env(map[string]string{"DRIFT_DETECTOR_CONCURRENT": "yes please"})}, nil, "DRIFT_DETECTOR_CONCURRENT: invalid boolean"},
+		{"bad format", LoadOptions{}, []string{"--format", "xml"}, `output format "xml" is not supported: use one of console, json`},
+		{"bad instance", LoadOptions{}, []string{"--instances", "i-123,web-1"}, `instance ID "web-1" is invalid`},
+		{"empty state file", LoadOptions{}, []string{"--terraform-state", ""}, "no Terraform state file set"},
+		{"bad fail-on", LoadOptions{}, []string{"--fail-on", "severity>=urgent"}, `unknown severity "urgent"`},
+		{"S3 bucket without key", LoadOptions{}, []string{"--state-bucket", "tf-state"}, "pass --state-bucket and --state-key together"},
+		{"PagerDuty state without key", LoadOptions{}, []string{"--pagerduty-state-file", "pd.json"}, "pass --pagerduty-routing-key"},
+		{"malformed attribute", LoadOptions{}, []string{"--attributes", "instance-type,tags.Env"}, `attribute "instance-type" is not a Terraform attribute name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.LookupEnv == nil {
+				tt.opts.LookupEnv = env(nil)
+			}
+			tt.opts.Flags = parseFlags(t, tt.args...)
+
+			_, err := Load(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	cfg := Default()
+	cfg.Attributes = []string{"instance_type", "tags.kubernetes.io/role", "user_data", "Bad-Name"}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Expected Bad-Name to be rejected")
+	}
+
+	cfg.Attributes = cfg.Attributes[:3]
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected unregistered attributes to be valid, got %v", err)
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"user_data" has no schema`) {
+		t.Errorf("Expected a warning for user_data only, got %v", warnings)
+	}
+}
@@ -0,0 +1,420 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes every environment variable read by Load
+const EnvPrefix = "DRIFT_DETECTOR_"
+
+// LoadOptions controls where Load reads configuration from
+type LoadOptions struct {
+	// File is a YAML or JSON config file. The --config flag and the
+	// DRIFT_DETECTOR_CONFIG variable take precedence over it.
+	File string
+
+	// Profile selects a named profile from the file. The --profile flag and
+	// the DRIFT_DETECTOR_PROFILE variable take precedence over it.
+	Profile string
+
+	// LookupEnv reads environment variables; os.LookupEnv when nil
+	LookupEnv func(key string) (string, bool)
+
+	// Flags is a parsed flag set created with RegisterFlags. Only flags set
+	// on the command line override other sources.
+	Flags *flag.FlagSet
+}
+
+// setting is a configuration value that can come from a flag or an
+// environment variable
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"terraform-state", "STATE_FILE", "Path to Terraform state file", func(c *Config, v string) error {
+		c.TerraformStateFile = v
+		return nil
+	}},
+	{"instances", "INSTANCES", "Comma-separated EC2 instance IDs (default: every instance in the state)", func(c *Config, v string) error {
+		c.InstanceIDs = splitList(v)
+		return nil
+	}},
+	{"attributes", "ATTRIBUTES", "Comma-separated attributes to check", func(c *Config, v string) error {
+		c.Attributes = splitList(v)
+		return nil
+	}},
+	{"mock", "MOCK", "Use mock EC2 data", func(c *Config, v string) error {
+		return parseBool(v, &c.UseMockData)
+	}},
+	{"concurrent", "CONCURRENT", "Check instances concurrently", func(c *Config, v string) error {
+		return parseBool(v, &c.Concurrent)
+	}},
+	{"format", "FORMAT", "Output format (" + strings.Join(OutputFormats, "/") + ")", func(c *Config, v string) error {
+		c.OutputFormat = v
+		return nil
+	}},
+	{"output", "OUTPUT", "Write the report to a file instead of stdout", func(c *Config, v string) error {
+		c.OutputFile = v
+		return nil
+	}},
+	{"region", "REGION", "AWS region (default: from the AWS config)", func(c *Config, v string) error {
+		c.Region = v
+		return nil
+	}},
+	{"sensitive-attributes", "SENSITIVE_ATTRIBUTES", "Comma-separated attribute paths to redact in reports", func(c *Config, v string) error {
+		c.SensitiveAttributes = splitList(v)
+		return nil
+	}},
+	{"unsafe-show-sensitive", "UNSAFE_SHOW_SENSITIVE", "Show sensitive values in reports verbatim", func(c *Config, v string) error {
+		return parseBool(v, &c.UnsafeShowSensitive)
+	}},
+	{"fail-on", "FAIL_ON", "Results that fail the run: drift, error, severity>=<level> or none", func(c *Config, v string) error {
+		c.FailOn = v
+		return nil
+	}},
+	{"state-bucket", "STATE_BUCKET", "S3 bucket of the Terraform state (instead of --terraform-state)", func(c *Config, v string) error {
+		c.StateBucket = v
+		return nil
+	}},
+	{"state-key", "STATE_KEY", "Key of the Terraform state in the S3 bucket", func(c *Config, v string) error {
+		c.StateKey = v
+		return nil
+	}},
+	{"state-region", "STATE_REGION", "Region of the S3 state bucket (default: --region)", func(c *Config, v string) error {
+		c.StateRegion = v
+		return nil
+	}},
+	{"state-workspace", "STATE_WORKSPACE", "Terraform workspace of the S3 state", func(c *Config, v string) error {
+		c.StateWorkspace = v
+		return nil
+	}},
+	{"stream-state", "STREAM_STATE", "Decode the state token by token to bound memory on very large states", func(c *Config, v string) error {
+		return parseBool(v, &c.StreamState)
+	}},
+	{"prometheus-textfile", "PROMETHEUS_TEXTFILE", "Also write Prometheus metrics atomically to this textfile collector file", func(c *Config, v string) error {
+		c.PrometheusTextfile = v
+		return nil
+	}},
+	{"pushgateway-url", "PUSHGATEWAY_URL", "Also push Prometheus metrics to this Pushgateway", func(c *Config, v string) error {
+		c.PushgatewayURL = v
+		return nil
+	}},
+	{"slack-webhook-url", "SLACK_WEBHOOK_URL", "Post drift to this Slack incoming webhook", func(c *Config, v string) error {
+		c.SlackWebhookURL = v
+		return nil
+	}},
+	{"webhook-url", "WEBHOOK_URL", "Post the JSON report to this URL when drift is found", func(c *Config, v string) error {
+		c.WebhookURL = v
+		return nil
+	}},
+	{"webhook-secret", "WEBHOOK_SECRET", "Sign webhook bodies with this secret", func(c *Config, v string) error {
+		c.WebhookSecret = v
+		return nil
+	}},
+	{"pagerduty-routing-key", "PAGERDUTY_ROUTING_KEY", "Page on critical drift with this PagerDuty Events v2 routing key", func(c *Config, v string) error {
+		c.PagerDutyRoutingKey = v
+		return nil
+	}},
+	{"pagerduty-state-file", "PAGERDUTY_STATE_FILE", "Track open PagerDuty incidents in this file", func(c *Config, v string) error {
+		c.PagerDutyStateFile = v
+		return nil
+	}},
+}
+
+var boolFlags = map[string]bool{"mock": true, "concurrent": true, "unsafe-show-sensitive": true, "stream-state": true}
+
+// RegisterFlags defines the configuration flags, plus --config and
+// --profile, on a flag set. Flag defaults are shown for documentation only;
+// Load applies defaults itself so unset flags never mask the file or
+// environment.
+func RegisterFlags(fs *flag.FlagSet) {
+	defaults := Default()
+
+	fs.String("config", "", "Path to a YAML or JSON config file")
+	fs.String("profile", "", "Named profile from the config file")
+
+	for _, s := range settings {
+		if boolFlags[s.flag] {
+			fs.Bool(s.flag, false, s.usage)
+			continue
+		}
+
+		def := ""
+		switch s.flag {
+		case "terraform-state":
+			def = defaults.TerraformStateFile
+		case "attributes":
+			def = strings.Join(defaults.Attributes, ",")
+		case "format":
+			def = defaults.OutputFormat
+		case "fail-on":
+			def = defaults.FailOn
+		}
+		fs.String(s.flag, def, s.usage)
+	}
+}
+
+// Load builds the configuration with the precedence flag > environment >
+// config file > default, then validates it
+func Load(opts LoadOptions) (Config, error) {
+	lookupEnv := opts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	setFlags := make(map[string]string)
+	if opts.Flags != nil {
+		opts.Flags.Visit(func(f *flag.Flag) {
+			setFlags[f.Name] = f.Value.String()
+		})
+	}
+
+	file := pick(opts.File, lookupEnv, "CONFIG", setFlags, "config")
+	profile := pick(opts.Profile, lookupEnv, "PROFILE", setFlags, "profile")
+
+	cfg := Default()
+
+	if file != "" {
+		if err := applyFile(&cfg, file, profile); err != nil {
+			return Config{}, err
+		}
+	} else if profile != "" {
+		return Config{}, fmt.Errorf("profile %q selected but no config file given: pass --config or set %sCONFIG", profile, EnvPrefix)
+	}
+
+	for _, s := range settings {
+		if value, ok := lookupEnv(EnvPrefix + s.env); ok {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("%s%s: %w", EnvPrefix, s.env, err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := setFlags[s.flag]; ok {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("--%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// pick resolves a value that itself selects configuration, like the file
+// path, with the same precedence as other settings
+func pick(fallback string, lookupEnv func(string) (string, bool), env string, setFlags map[string]string, flagName string) string {
+	if value, ok := setFlags[flagName]; ok {
+		return value
+	}
+	if value, ok := lookupEnv(EnvPrefix + env); ok {
+		return value
+	}
+	return fallback
+}
+
+// fileConfig is the config file layout. Pointers and nil slices tell keys
+// that are absent from keys set to their zero value.
+type fileConfig struct {
+	StateFile           *string  `yaml:"state_file" json:"state_file"`
+	Instances           []string `yaml:"instances" json:"instances"`
+	Attributes          []string `yaml:"attributes" json:"attributes"`
+	Mock                *bool    `yaml:"mock" json:"mock"`
+	Concurrent          *bool    `yaml:"concurrent" json:"concurrent"`
+	Format              *string  `yaml:"format" json:"format"`
+	Output              *string  `yaml:"output" json:"output"`
+	Region              *string  `yaml:"region" json:"region"`
+	SensitiveAttributes []string `yaml:"sensitive_attributes" json:"sensitive_attributes"`
+	UnsafeShowSensitive *bool    `yaml:"unsafe_show_sensitive" json:"unsafe_show_sensitive"`
+	FailOn              *string  `yaml:"fail_on" json:"fail_on"`
+	StateBucket         *string  `yaml:"state_bucket" json:"state_bucket"`
+	StateKey            *string  `yaml:"state_key" json:"state_key"`
+	StateRegion         *string  `yaml:"state_region" json:"state_region"`
+	StateWorkspace      *string  `yaml:"state_workspace" json:"state_workspace"`
+	StreamState         *bool    `yaml:"stream_state" json:"stream_state"`
+	PrometheusTextfile  *string  `yaml:"prometheus_textfile" json:"prometheus_textfile"`
+	PushgatewayURL      *string  `yaml:"pushgateway_url" json:"pushgateway_url"`
+	SlackWebhookURL     *string  `yaml:"slack_webhook_url" json:"slack_webhook_url"`
+	WebhookURL          *string  `yaml:"webhook_url" json:"webhook_url"`
+	WebhookSecret       *string  `yaml:"webhook_secret" json:"webhook_secret"`
+	PagerDutyRoutingKey *string  `yaml:"pagerduty_routing_key" json:"pagerduty_routing_key"`
+	PagerDutyStateFile  *string  `yaml:"pagerduty_state_file" json:"pagerduty_state_file"`
+}
+
+// configFile is a config file: top-level settings shared by every profile,
+// and named profiles that override them
+type configFile struct {
+	fileConfig `yaml:",inline"`
+	Profiles   map[string]fileConfig `yaml:"profiles" json:"profiles"`
+}
+
+// applyFile applies the top-level settings of a config file and then the
+// selected profile
+func applyFile(cfg *Config, path, profile string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file configFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = decodeJSONFile(data, &file)
+	} else {
+		err = decodeYAMLFile(data, &file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	file.fileConfig.apply(cfg)
+
+	if profile == "" {
+		return nil
+	}
+	selected, ok := file.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s: available profiles are %s", profile, path, profileNames(file.Profiles))
+	}
+	selected.apply(cfg)
+	return nil
+}
+
+func decodeYAMLFile(data []byte, file *configFile) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func decodeJSONFile(data []byte, file *configFile) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(file)
+}
+
+func (f fileConfig) apply(cfg *Config) {
+	if f.StateFile != nil {
+		cfg.TerraformStateFile = *f.StateFile
+	}
+	if f.Instances != nil {
+		cfg.InstanceIDs = f.Instances
+	}
+	if f.Attributes != nil {
+		cfg.Attributes = f.Attributes
+	}
+	if f.Mock != nil {
+		cfg.UseMockData = *f.Mock
+	}
+	if f.Concurrent != nil {
+		cfg.Concurrent = *f.Concurrent
+	}
+	if f.Format != nil {
+		cfg.OutputFormat = *f.Format
+	}
+	if f.Output != nil {
+		cfg.OutputFile = *f.Output
+	}
+	if f.Region != nil {
+		cfg.Region = *f.Region
+	}
+	if f.SensitiveAttributes != nil {
+		cfg.SensitiveAttributes = f.SensitiveAttributes
+	}
+	if f.UnsafeShowSensitive != nil {
+		cfg.UnsafeShowSensitive = *f.UnsafeShowSensitive
+	}
+	if f.FailOn != nil {
+		cfg.FailOn = *f.FailOn
+	}
+	if f.StateBucket != nil {
+		cfg.StateBucket = *f.StateBucket
+	}
+	if f.StateKey != nil {
+		cfg.StateKey = *f.StateKey
+	}
+	if f.StateRegion != nil {
+		cfg.StateRegion = *f.StateRegion
+	}
+	if f.StateWorkspace != nil {
+		cfg.StateWorkspace = *f.StateWorkspace
+	}
+	if f.StreamState != nil {
+		cfg.StreamState = *f.StreamState
+	}
+	if f.PrometheusTextfile != nil {
+		cfg.PrometheusTextfile = *f.PrometheusTextfile
+	}
+	if f.PushgatewayURL != nil {
+		cfg.PushgatewayURL = *f.PushgatewayURL
+	}
+	if f.SlackWebhookURL != nil {
+		cfg.SlackWebhookURL = *f.SlackWebhookURL
+	}
+	if f.WebhookURL != nil {
+		cfg.WebhookURL = *f.WebhookURL
+	}
+	if f.WebhookSecret != nil {
+		cfg.WebhookSecret = *f.WebhookSecret
+	}
+	if f.PagerDutyRoutingKey != nil {
+		cfg.PagerDutyRoutingKey = *f.PagerDutyRoutingKey
+	}
+	if f.PagerDutyStateFile != nil {
+		cfg.PagerDutyStateFile = *f.PagerDutyStateFile
+	}
+}
+
+func profileNames(profiles map[string]fileConfig) string {
+	if len(profiles) == 0 {
+		return "none (add a profiles section)"
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+func parseBool(value string, target *bool) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q: use true or false", value)
+	}
+	*target = parsed
+	return nil
+}
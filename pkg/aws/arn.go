@@ -0,0 +1,22 @@
+package aws
+
+import "strings"
+
+// InstanceProfileNameFromARN resolves an IAM instance profile ARN such as
+// arn:aws:iam::123456789012:instance-profile/path/name to the profile name.
+// Values that are not instance profile ARNs are returned unchanged.
+func InstanceProfileNameFromARN(arn string) string {
+	const marker = ":instance-profile/"
+
+	if !strings.HasPrefix(arn, "arn:") {
+		return arn
+	}
+
+	idx := strings.Index(arn, marker)
+	if idx < 0 {
+		return arn
+	}
+
+	path := arn[idx+len(marker):]
+	return path[strings.LastIndex(path, "/")+1:]
+}
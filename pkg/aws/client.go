@@ -2,8 +2,45 @@ package aws
 
 import (
 	"context"
+	"time"
 )
 
 type EC2Client interface {
 	GetInstance(ctx context.Context, instanceID string) (map[string]interface{}, error)
-}
\ No newline at end of file
+	// GetInstances fetches several instances at once, keyed by instance ID.
+	// Instances that do not exist are omitted from the result. When only
+	// some lookups fail, the others are returned with a *BatchError.
+	GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]interface{}, error)
+	// ListInstances returns every instance in the account matching the filter
+	ListInstances(ctx context.Context, filter InstanceFilter) ([]InstanceSummary, error)
+}
+
+// InstanceFilter narrows the instances returned by ListInstances. Empty
+// fields do not filter, except States.
+type InstanceFilter struct {
+	Tags   map[string]string // Tag key to value; an empty value matches any value
+	VpcIDs []string
+	States []string // Instance state names, e.g. running; defaults to DefaultInstanceStates
+}
+
+// DefaultInstanceStates are the states listed when a filter names none.
+// Terminated and shutting-down instances are gone or about to be, so they
+// are not worth reporting as unmanaged.
+var DefaultInstanceStates = []string{"pending", "running", "stopping", "stopped"}
+
+// states returns the instance states the filter selects
+func (f InstanceFilter) states() []string {
+	if len(f.States) > 0 {
+		return f.States
+	}
+	return DefaultInstanceStates
+}
+
+// InstanceSummary describes an instance found while listing the account
+type InstanceSummary struct {
+	InstanceID string
+	State      string
+	VpcID      string
+	Tags       map[string]string
+	LaunchTime time.Time
+}
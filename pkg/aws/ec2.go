@@ -2,17 +2,29 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
 )
 
+// maxFilterValues is the maximum number of values DescribeInstances accepts
+// in a single filter
+const maxFilterValues = 200
+
+// maxResultsPerPage is the largest page size DescribeInstances returns
+const maxResultsPerPage = 1000
+
 type AWSEC2Client struct {
-	client *ec2.Client
+	client ec2.DescribeInstancesAPIClient
 }
 
-func NewAWSEC2Client(client *ec2.Client) *AWSEC2Client {
+// NewAWSEC2Client wraps an EC2 API client. Only DescribeInstances is used, so
+// tests can pass a stub.
+func NewAWSEC2Client(client ec2.DescribeInstancesAPIClient) *AWSEC2Client {
 	return &AWSEC2Client{client: client}
 }
 
@@ -23,17 +35,181 @@ func (c *AWSEC2Client) GetInstance(ctx context.Context, instanceID string) (map[
 
 	result, err := c.client.DescribeInstances(ctx, input)
 	if err != nil {
+		if isInstanceNotFound(err) {
+			return nil, fmt.Errorf("instance %s: %w", instanceID, ErrInstanceNotFound)
+		}
 		return nil, fmt.Errorf("failed to describe instance: %w", err)
 	}
 
 	if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 {
-		return nil, fmt.Errorf("instance %s not found", instanceID)
+		return nil, fmt.Errorf("instance %s: %w", instanceID, ErrInstanceNotFound)
 	}
 
 	instance := result.Reservations[0].Instances[0]
+	if isTerminated(instance) {
+		return nil, fmt.Errorf("instance %s is terminated: %w", instanceID, ErrInstanceNotFound)
+	}
 	return instanceToMap(instance), nil
 }
 
+// ListInstances pages through DescribeInstances with server-side filters
+func (c *AWSEC2Client) ListInstances(ctx context.Context, filter InstanceFilter) ([]InstanceSummary, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters:    describeFilters(filter),
+		MaxResults: awssdk.Int32(maxResultsPerPage),
+	}
+
+	summaries := make([]InstanceSummary, 0)
+
+	paginator := ec2.NewDescribeInstancesPaginator(c.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list instances: %w", err)
+		}
+
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				summaries = append(summaries, instanceToSummary(instance))
+			}
+		}
+	}
+
+	return summaries, nil
+}
+
+// describeFilters converts an InstanceFilter to DescribeInstances filters
+func describeFilters(filter InstanceFilter) []types.Filter {
+	filters := make([]types.Filter, 0)
+
+	for key, value := range filter.Tags {
+		if value == "" {
+			filters = append(filters, types.Filter{
+				Name:   awssdk.String("tag-key"),
+				Values: []string{key},
+			})
+			continue
+		}
+		filters = append(filters, types.Filter{
+			Name:   awssdk.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+
+	if len(filter.VpcIDs) > 0 {
+		filters = append(filters, types.Filter{
+			Name:   awssdk.String("vpc-id"),
+			Values: filter.VpcIDs,
+		})
+	}
+
+	filters = append(filters, types.Filter{
+		Name:   awssdk.String("instance-state-name"),
+		Values: filter.states(),
+	})
+
+	return filters
+}
+
+func instanceToSummary(instance types.Instance) InstanceSummary {
+	summary := InstanceSummary{
+		Tags: make(map[string]string, len(instance.Tags)),
+	}
+
+	if instance.InstanceId != nil {
+		summary.InstanceID = *instance.InstanceId
+	}
+	if instance.State != nil {
+		summary.State = string(instance.State.Name)
+	}
+	if instance.VpcId != nil {
+		summary.VpcID = *instance.VpcId
+	}
+	if instance.LaunchTime != nil {
+		summary.LaunchTime = *instance.LaunchTime
+	}
+	for _, tag := range instance.Tags {
+		if tag.Key != nil && tag.Value != nil {
+			summary.Tags[*tag.Key] = *tag.Value
+		}
+	}
+
+	return summary
+}
+
+// isInstanceNotFound reports whether an API error means the instance ID
+// does not exist
+func isInstanceNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "InvalidInstanceID.NotFound", "InvalidInstanceID.Malformed":
+		return true
+	default:
+		return false
+	}
+}
+
+// isTerminated reports whether an instance is terminated; DescribeInstances
+// keeps returning terminated instances for a while after termination
+func isTerminated(instance types.Instance) bool {
+	return instance.State != nil && instance.State.Name == types.InstanceStateNameTerminated
+}
+
+// GetInstances fetches instances in batches using an instance-id filter, so
+// unknown IDs are skipped instead of failing the whole request. A failed
+// batch does not stop the others; its instances are reported in a
+// *BatchError.
+func (c *AWSEC2Client) GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]interface{}, error) {
+	instances := make(map[string]map[string]interface{}, len(instanceIDs))
+	failed := make(map[string]error)
+
+	for start := 0; start < len(instanceIDs); start += maxFilterValues {
+		end := min(start+maxFilterValues, len(instanceIDs))
+
+		input := &ec2.DescribeInstancesInput{
+			Filters: []types.Filter{
+				{
+					Name:   awssdk.String("instance-id"),
+					Values: instanceIDs[start:end],
+				},
+			},
+			MaxResults: awssdk.Int32(maxResultsPerPage),
+		}
+
+		paginator := ec2.NewDescribeInstancesPaginator(c.client, input)
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				// Instances from earlier pages of the batch were found
+				err = fmt.Errorf("failed to describe instances: %w", err)
+				for _, id := range instanceIDs[start:end] {
+					if _, found := instances[id]; !found {
+						failed[id] = err
+					}
+				}
+				break
+			}
+
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					if instance.InstanceId != nil && !isTerminated(instance) {
+						instances[*instance.InstanceId] = instanceToMap(instance)
+					}
+				}
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return instances, &BatchError{Errors: failed}
+	}
+	return instances, nil
+}
+
 func instanceToMap(instance types.Instance) map[string]interface{} {
 	config := make(map[string]interface{})
 
@@ -90,10 +266,29 @@ func instanceToMap(instance types.Instance) map[string]interface{} {
 		config["monitoring"] = string(instance.Monitoring.State)
 	}
 
+	// Root block device
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.DeviceName == nil || instance.RootDeviceName == nil || *mapping.DeviceName != *instance.RootDeviceName {
+			continue
+		}
+		rootDevice := map[string]interface{}{
+			"device_name": *mapping.DeviceName,
+		}
+		if mapping.Ebs != nil {
+			if mapping.Ebs.VolumeId != nil {
+				rootDevice["volume_id"] = *mapping.Ebs.VolumeId
+			}
+			if mapping.Ebs.DeleteOnTermination != nil {
+				rootDevice["delete_on_termination"] = *mapping.Ebs.DeleteOnTermination
+			}
+		}
+		config["root_block_device"] = rootDevice
+	}
+
 	// IAM instance profile
 	if instance.IamInstanceProfile != nil && instance.IamInstanceProfile.Arn != nil {
 		config["iam_instance_profile"] = *instance.IamInstanceProfile.Arn
 	}
 
 	return config
-}
\ No newline at end of file
+}
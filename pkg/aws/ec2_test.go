@@ -0,0 +1,258 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// stubEC2 serves DescribeInstances from a fixed set of instances, honouring
+// the instance-id filter and splitting results into pages of pageSize
+type stubEC2 struct {
+	instances []types.Instance
+	pageSize  int
+	err       error
+	failCall  int // 1-based call that fails with err; every call when zero
+	calls     []*ec2.DescribeInstancesInput
+}
+
+func (s *stubEC2) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	s.calls = append(s.calls, params)
+	if s.err != nil && (s.failCall == 0 || s.failCall == len(s.calls)) {
+		return nil, s.err
+	}
+
+	wanted := make(map[string]bool)
+	for _, filter := range params.Filters {
+		if awssdk.ToString(filter.Name) == "instance-id" {
+			for _, id := range filter.Values {
+				wanted[id] = true
+			}
+		}
+	}
+
+	matched := make([]types.Instance, 0)
+	for _, instance := range s.instances {
+		if len(wanted) == 0 || wanted[awssdk.ToString(instance.InstanceId)] {
+			matched = append(matched, instance)
+		}
+	}
+
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := len(matched)
+	if s.pageSize > 0 {
+		end = min(start+s.pageSize, len(matched))
+	}
+
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: matched[start:end]}},
+	}
+	if end < len(matched) {
+		output.NextToken = awssdk.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+func stubInstance(id string, state types.InstanceStateName) types.Instance {
+	return types.Instance{
+		InstanceId:   awssdk.String(id),
+		InstanceType: types.InstanceTypeT3Micro,
+		State:        &types.InstanceState{Name: state},
+	}
+}
+
+func stubInstances(ids []string) []types.Instance {
+	instances := make([]types.Instance, len(ids))
+	for i, id := range ids {
+		instances[i] = stubInstance(id, types.InstanceStateNameRunning)
+	}
+	return instances
+}
+
+func instanceIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("i-%017d", i)
+	}
+	return ids
+}
+
+func TestGetInstances(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    []types.Instance
+		pageSize    int
+		request     []string
+		found       []string
+		missing     []string
+		filterSizes []int // Instance-id filter size of each DescribeInstances call
+	}{
+		{
+			name:        "batches more than 200 IDs",
+			existing:    stubInstances(instanceIDs(450)),
+			request:     instanceIDs(450),
+			found:       instanceIDs(450),
+			filterSizes: []int{200, 200, 50},
+		},
+		{
+			name:        "follows pagination",
+			existing:    stubInstances(instanceIDs(5)),
+			pageSize:    2,
+			request:     instanceIDs(5),
+			found:       instanceIDs(5),
+			filterSizes: []int{5, 5, 5},
+		},
+		{
+			name: "skips terminated instances in a page",
+			existing: []types.Instance{
+				stubInstance("i-running", types.InstanceStateNameRunning),
+				stubInstance("i-terminated", types.InstanceStateNameTerminated),
+				stubInstance("i-stopped", types.InstanceStateNameStopped),
+			},
+			pageSize:    2,
+			request:     []string{"i-running", "i-terminated", "i-stopped"},
+			found:       []string{"i-running", "i-stopped"},
+			missing:     []string{"i-terminated"},
+			filterSizes: []int{3, 3},
+		},
+		{
+			name:        "omits unknown IDs",
+			existing:    []types.Instance{stubInstance("i-known", types.InstanceStateNameRunning)},
+			request:     []string{"i-known", "i-unknown"},
+			found:       []string{"i-known"},
+			missing:     []string{"i-unknown"},
+			filterSizes: []int{2},
+		},
+		{
+			name:        "no IDs",
+			filterSizes: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubEC2{instances: tt.existing, pageSize: tt.pageSize}
+			client := NewAWSEC2Client(stub)
+
+			instances, err := client.GetInstances(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, id := range tt.found {
+				if instances[id] == nil {
+					t.Errorf("Expected %s to be found", id)
+				}
+			}
+			for _, id := range tt.missing {
+				if _, ok := instances[id]; ok {
+					t.Errorf("Expected %s to be omitted", id)
+				}
+			}
+
+			if len(stub.calls) != len(tt.filterSizes) {
+				t.Fatalf("Expected %d DescribeInstances calls, got %d", len(tt.filterSizes), len(stub.calls))
+			}
+			for i, call := range stub.calls {
+				if len(call.Filters) != 1 || awssdk.ToString(call.Filters[0].Name) != "instance-id" {
+					t.Fatalf("Expected a single instance-id filter, got %+v", call.Filters)
+				}
+				if len(call.Filters[0].Values) != tt.filterSizes[i] {
+					t.Errorf("Call %d: expected %d filter values, got %d", i, tt.filterSizes[i], len(call.Filters[0].Values))
+				}
+				if len(call.InstanceIds) != 0 {
+					t.Errorf("Call %d: expected no InstanceIds, got %v", i, call.InstanceIds)
+				}
+			}
+		})
+	}
+}
+
+func TestGetInstances_Error(t *testing.T) {
+	apiErr := errors.New("throttled")
+	client := NewAWSEC2Client(&stubEC2{err: apiErr})
+
+	_, err := client.GetInstances(context.Background(), []string{"i-1"})
+	if !errors.Is(err, apiErr) {
+		t.Errorf("Expected wrapped API error, got %v", err)
+	}
+}
+
+func TestGetInstances_PartialFailure(t *testing.T) {
+	apiErr := errors.New("throttled")
+	stub := &stubEC2{instances: stubInstances(instanceIDs(450)), err: apiErr, failCall: 2}
+	client := NewAWSEC2Client(stub)
+
+	ids := instanceIDs(450)
+	instances, err := client.GetInstances(context.Background(), ids)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *BatchError, got %v", err)
+	}
+	if !errors.Is(err, apiErr) {
+		t.Errorf("Expected wrapped API error, got %v", err)
+	}
+	if len(batchErr.Errors) != 200 || batchErr.Errors[ids[200]] == nil || batchErr.Errors[ids[399]] == nil {
+		t.Errorf("Expected the 200 instances of the second batch to fail, got %d", len(batchErr.Errors))
+	}
+	if len(instances) != 250 || instances[ids[0]] == nil || instances[ids[449]] == nil {
+		t.Errorf("Expected the other 250 instances to be found, got %d", len(instances))
+	}
+}
+
+func TestDescribeFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter InstanceFilter
+		want   map[string][]string
+	}{
+		{
+			name:   "excludes terminated and shutting-down by default",
+			filter: InstanceFilter{},
+			want: map[string][]string{
+				"instance-state-name": {"pending", "running", "stopping", "stopped"},
+			},
+		},
+		{
+			name:   "explicit states",
+			filter: InstanceFilter{States: []string{"terminated"}},
+			want: map[string][]string{
+				"instance-state-name": {"terminated"},
+			},
+		},
+		{
+			name:   "tags and VPCs",
+			filter: InstanceFilter{Tags: map[string]string{"Owner": "ops", "Team": ""}, VpcIDs: []string{"vpc-1"}},
+			want: map[string][]string{
+				"tag:Owner":           {"ops"},
+				"tag-key":             {"Team"},
+				"vpc-id":              {"vpc-1"},
+				"instance-state-name": {"pending", "running", "stopping", "stopped"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := describeFilters(tt.filter)
+
+			got := make(map[string][]string, len(filters))
+			for _, filter := range filters {
+				got[awssdk.ToString(filter.Name)] = filter.Values
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected filters %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInstanceNotFound is returned when an instance does not exist in the
+// account or has already been terminated
+var ErrInstanceNotFound = errors.New("instance not found")
+
+// BatchError is returned by GetInstances when some DescribeInstances calls
+// failed. The instances from the other calls are still returned; Errors
+// holds the failure for each instance that could not be looked up.
+type BatchError struct {
+	Errors map[string]error // Keyed by instance ID
+}
+
+func (e *BatchError) Error() string {
+	for _, err := range e.Errors {
+		return fmt.Sprintf("failed to describe %d instances: %v", len(e.Errors), err)
+	}
+	return "failed to describe instances"
+}
+
+// Unwrap returns the distinct underlying errors
+func (e *BatchError) Unwrap() []error {
+	seen := make(map[error]bool)
+	errs := make([]error, 0)
+	for _, err := range e.Errors {
+		if !seen[err] {
+			seen[err] = true
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -3,6 +3,9 @@ package aws
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"time"
 )
 
 type MockEC2Client struct {
@@ -27,7 +30,8 @@ func NewMockEC2Client() *MockEC2Client {
 					"Environment": "production",
 					"ManagedBy":   "terraform",
 				},
-				"monitoring": "disabled",
+				"monitoring":           "disabled",
+				"iam_instance_profile": "arn:aws:iam::123456789012:instance-profile/web-server-profile",
 			},
 			"i-0987654321fedcba0": {
 				"instance_type": "t3.large",
@@ -45,6 +49,22 @@ func NewMockEC2Client() *MockEC2Client {
 				},
 				"monitoring": "enabled",
 			},
+			// Launched by hand and never imported into Terraform
+			"i-0a1b2c3d4e5f67890": {
+				"instance_type": "t3.xlarge",
+				"ami":           "ami-0c55b159cbfafe1f0",
+				"subnet_id":     "subnet-12345678",
+				"vpc_id":        "vpc-12345678",
+				"key_name":      "my-key-pair",
+				"vpc_security_group_ids": []string{
+					"sg-12345678",
+				},
+				"tags": map[string]interface{}{
+					"Name":  "debug-box",
+					"Owner": "ops",
+				},
+				"monitoring": "disabled",
+			},
 		},
 	}
 }
@@ -52,13 +72,81 @@ func NewMockEC2Client() *MockEC2Client {
 func (m *MockEC2Client) GetInstance(ctx context.Context, instanceID string) (map[string]interface{}, error) {
 	instance, exists := m.instances[instanceID]
 	if !exists {
-		return nil, fmt.Errorf("instance %s not found in mock data", instanceID)
+		return nil, fmt.Errorf("instance %s not found in mock data: %w", instanceID, ErrInstanceNotFound)
 	}
 
 	// Return a copy to prevent modifications
 	return copyMap(instance), nil
 }
 
+func (m *MockEC2Client) GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]interface{}, error) {
+	instances := make(map[string]map[string]interface{}, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		if instance, exists := m.instances[instanceID]; exists {
+			instances[instanceID] = copyMap(instance)
+		}
+	}
+	return instances, nil
+}
+
+// ListInstances returns all mock instances as running, launched at mockLaunchTime
+func (m *MockEC2Client) ListInstances(ctx context.Context, filter InstanceFilter) ([]InstanceSummary, error) {
+	ids := make([]string, 0, len(m.instances))
+	for id := range m.instances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	summaries := make([]InstanceSummary, 0, len(ids))
+	for _, id := range ids {
+		instance := m.instances[id]
+
+		summary := InstanceSummary{
+			InstanceID: id,
+			State:      "running",
+			LaunchTime: mockLaunchTime,
+			Tags:       make(map[string]string),
+		}
+		if vpcID, ok := instance["vpc_id"].(string); ok {
+			summary.VpcID = vpcID
+		}
+		if tags, ok := instance["tags"].(map[string]interface{}); ok {
+			for key, value := range tags {
+				summary.Tags[key] = fmt.Sprintf("%v", value)
+			}
+		}
+
+		if matchesFilter(summary, filter) {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return summaries, nil
+}
+
+// mockLaunchTime is the launch time reported for every mock instance
+var mockLaunchTime = time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC)
+
+// matchesFilter applies an InstanceFilter the way DescribeInstances would
+func matchesFilter(summary InstanceSummary, filter InstanceFilter) bool {
+	for key, value := range filter.Tags {
+		tagValue, exists := summary.Tags[key]
+		if !exists || (value != "" && tagValue != value) {
+			return false
+		}
+	}
+
+	if len(filter.VpcIDs) > 0 && !slices.Contains(filter.VpcIDs, summary.VpcID) {
+		return false
+	}
+
+	if !slices.Contains(filter.states(), summary.State) {
+		return false
+	}
+
+	return true
+}
+
 func copyMap(src map[string]interface{}) map[string]interface{} {
 	dst := make(map[string]interface{})
 	for k, v := range src {
@@ -74,4 +162,4 @@ func copyMap(src map[string]interface{}) map[string]interface{} {
 		}
 	}
 	return dst
-}
\ No newline at end of file
+}
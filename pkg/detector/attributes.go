@@ -0,0 +1,196 @@
+package detector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+)
+
+// NewDefaultRegistry creates a registry containing the built-in EC2 attributes
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	stringAttributes := []struct {
+		name        string
+		description string
+		severity    Severity
+	}{
+		{"ami", "AMI ID the instance was launched from", SeverityHigh},
+		{"instance_type", "EC2 instance type", SeverityMedium},
+		{"subnet_id", "VPC subnet the instance is placed in", SeverityHigh},
+		{"vpc_id", "VPC the instance belongs to", SeverityHigh},
+		{"key_name", "Name of the EC2 key pair used for SSH access", SeverityHigh},
+		{"private_ip", "Primary private IPv4 address", SeverityMedium},
+		{"public_ip", "Public IPv4 address", SeverityHigh},
+	}
+	for _, attr := range stringAttributes {
+		r.MustRegister(AttributeSchema{
+			Name:               attr.name,
+			Type:               TypeString,
+			Description:        attr.description,
+			NormalizeAWS:       normalizeString,
+			NormalizeTerraform: normalizeString,
+			Severity:           attr.severity,
+		})
+	}
+
+	r.MustRegister(AttributeSchema{
+		Name:               "vpc_security_group_ids",
+		Type:               TypeSet,
+		Description:        "Security group IDs attached to the instance (order-insensitive)",
+		NormalizeAWS:       normalizeStringSet,
+		NormalizeTerraform: normalizeStringSet,
+		Compare:            compareSet,
+		Severity:           SeverityCritical,
+	})
+
+	r.MustRegister(AttributeSchema{
+		Name:               "security_groups",
+		Type:               TypeSet,
+		Description:        "Security group names attached to the instance (order-insensitive)",
+		NormalizeAWS:       normalizeStringSet,
+		NormalizeTerraform: normalizeStringSet,
+		Compare:            compareSet,
+		Severity:           SeverityCritical,
+	})
+
+	r.MustRegister(AttributeSchema{
+		Name:               "tags",
+		Type:               TypeMap,
+		Description:        "Resource tags; a missing tag map is treated as empty",
+		NormalizeAWS:       normalizeStringMap,
+		NormalizeTerraform: normalizeStringMap,
+		Severity:           SeverityLow,
+	})
+
+	r.MustRegister(AttributeSchema{
+		Name:               "monitoring",
+		Type:               TypeBool,
+		Description:        "Whether detailed CloudWatch monitoring is enabled",
+		NormalizeAWS:       normalizeMonitoringState,
+		NormalizeTerraform: normalizeBool,
+		Severity:           SeverityLow,
+	})
+
+	r.MustRegister(AttributeSchema{
+		Name:               "iam_instance_profile",
+		Type:               TypeString,
+		Description:        "Name of the IAM instance profile attached to the instance",
+		NormalizeAWS:       normalizeInstanceProfile,
+		NormalizeTerraform: normalizeInstanceProfile,
+		Severity:           SeverityCritical,
+	})
+
+	r.MustRegister(AttributeSchema{
+		Name:               "root_block_device",
+		Type:               TypeBlock,
+		Description:        "Root EBS volume settings; only fields known on both sides are compared",
+		NormalizeAWS:       normalizeBlock,
+		NormalizeTerraform: normalizeBlock,
+		Compare:            compareBlock,
+		Severity:           SeverityMedium,
+	})
+
+	return r
+}
+
+// normalizeString treats empty strings as unset
+func normalizeString(val any) any {
+	if s, ok := val.(string); ok && s == "" {
+		return nil
+	}
+	return val
+}
+
+// normalizeBool converts boolean-like values to bool
+func normalizeBool(val any) any {
+	switch v := val.(type) {
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case nil:
+		return false
+	}
+	return val
+}
+
+// normalizeMonitoringState maps the EC2 monitoring state to the boolean
+// Terraform stores. "pending" means monitoring is being enabled.
+func normalizeMonitoringState(val any) any {
+	if s, ok := val.(string); ok {
+		switch strings.ToLower(s) {
+		case "enabled", "pending":
+			return true
+		case "disabled", "disabling":
+			return false
+		}
+	}
+	return normalizeBool(val)
+}
+
+// normalizeInstanceProfile reduces an instance profile ARN to its name,
+// which is what the AWS provider stores in state
+func normalizeInstanceProfile(val any) any {
+	if s, ok := val.(string); ok {
+		return normalizeString(aws.InstanceProfileNameFromARN(s))
+	}
+	return val
+}
+
+// normalizeStringSet converts set values to a string slice
+func normalizeStringSet(val any) any {
+	if set, ok := toStringSet(val); ok {
+		return set
+	}
+	return val
+}
+
+// normalizeStringMap converts map values to strings, treating nil as empty
+func normalizeStringMap(val any) any {
+	switch v := val.(type) {
+	case nil:
+		return map[string]any{}
+	case map[string]string:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = item
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = fmt.Sprintf("%v", item)
+		}
+		return result
+	default:
+		return val
+	}
+}
+
+// normalizeBlock unwraps single nested blocks, which Terraform stores as a
+// list with one element
+func normalizeBlock(val any) any {
+	switch v := val.(type) {
+	case []any:
+		if len(v) == 1 {
+			return normalizeBlock(v[0])
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []map[string]any:
+		if len(v) == 1 {
+			return v[0]
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	default:
+		return val
+	}
+}
@@ -1,6 +1,10 @@
 package detector
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
 
 // getNestedValue retrieves a value from a nested map using dot notation
 func getNestedValue(data map[string]any, path string) any {
@@ -134,4 +138,97 @@ func mapsEqual(a, b map[string]any) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+// compareValues is the default comparator for attributes without a
+// dedicated comparison
+func compareValues(awsValue, tfValue any) *AttributeDrift {
+	if valuesEqual(awsValue, tfValue) {
+		return nil
+	}
+	return &AttributeDrift{}
+}
+
+// compareSet compares set-typed attributes regardless of member order
+func compareSet(awsValue, tfValue any) *AttributeDrift {
+	awsSet, awsOK := toStringSet(awsValue)
+	tfSet, tfOK := toStringSet(tfValue)
+	if !awsOK || !tfOK {
+		return compareValues(awsValue, tfValue)
+	}
+
+	added, removed := setDiff(awsSet, tfSet)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	return &AttributeDrift{
+		Added:   added,
+		Removed: removed,
+	}
+}
+
+// compareBlock compares nested blocks on the fields present on both sides,
+// since AWS and Terraform rarely expose the same set of block fields
+func compareBlock(awsValue, tfValue any) *AttributeDrift {
+	awsBlock, awsOK := awsValue.(map[string]any)
+	tfBlock, tfOK := tfValue.(map[string]any)
+	if !awsOK || !tfOK {
+		return compareValues(awsValue, tfValue)
+	}
+
+	for key, awsField := range awsBlock {
+		tfField, exists := tfBlock[key]
+		if !exists {
+			continue
+		}
+		if !valuesEqual(awsField, tfField) {
+			return &AttributeDrift{}
+		}
+	}
+
+	return nil
+}
+
+// toStringSet converts a set-typed value to a string slice
+func toStringSet(val any) ([]string, bool) {
+	switch v := val.(type) {
+	case nil:
+		return []string{}, true
+	case []string:
+		return v, true
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprintf("%v", item))
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// setDiff compares two sets as multisets and returns the members present
+// only in aws (added) and only in tf (removed), sorted for stable output
+func setDiff(aws, tf []string) (added, removed []string) {
+	counts := make(map[string]int, len(aws))
+	for _, item := range aws {
+		counts[item]++
+	}
+	for _, item := range tf {
+		counts[item]--
+	}
+
+	for item, count := range counts {
+		for ; count > 0; count-- {
+			added = append(added, item)
+		}
+		for ; count < 0; count++ {
+			removed = append(removed, item)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -77,4 +77,40 @@ func TestSplitPath(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSetDiff(t *testing.T) {
+	tests := []struct {
+		name            string
+		aws             []string
+		tf              []string
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{"same order", []string{"a", "b"}, []string{"a", "b"}, nil, nil},
+		{"different order", []string{"b", "a"}, []string{"a", "b"}, nil, nil},
+		{"added in aws", []string{"a", "c", "b"}, []string{"a", "b"}, []string{"c"}, nil},
+		{"removed in aws", []string{"a"}, []string{"b", "a"}, nil, []string{"b"}},
+		{"duplicates", []string{"a", "a"}, []string{"a"}, []string{"a"}, nil},
+		{"both", []string{"x", "a"}, []string{"a", "y"}, []string{"x"}, []string{"y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := setDiff(tt.aws, tt.tf)
+			if !valuesEqual(nilToEmpty(added), nilToEmpty(tt.expectedAdded)) {
+				t.Errorf("Expected added %v, got %v", tt.expectedAdded, added)
+			}
+			if !valuesEqual(nilToEmpty(removed), nilToEmpty(tt.expectedRemoved)) {
+				t.Errorf("Expected removed %v, got %v", tt.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func nilToEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
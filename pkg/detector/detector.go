@@ -2,7 +2,9 @@ package detector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
@@ -13,14 +15,41 @@ type Detector struct {
 	ec2Client  aws.EC2Client
 	tfParser   terraform.Parser
 	attributes []string
+	registry   *Registry
+	sensitive  []string
 }
 
-func New(ec2Client aws.EC2Client, tfParser terraform.Parser, attributes []string) *Detector {
-	return &Detector{
+// Option configures optional Detector behaviour
+type Option func(*Detector)
+
+// WithRegistry sets the attribute registry used for normalization and comparison
+func WithRegistry(registry *Registry) Option {
+	return func(d *Detector) {
+		d.registry = registry
+	}
+}
+
+// WithSensitiveAttributes marks additional attribute paths (e.g. user_data
+// or tags.Password) as sensitive, on top of those flagged by Terraform
+func WithSensitiveAttributes(paths ...string) Option {
+	return func(d *Detector) {
+		d.sensitive = append(d.sensitive, paths...)
+	}
+}
+
+func New(ec2Client aws.EC2Client, tfParser terraform.Parser, attributes []string, opts ...Option) *Detector {
+	d := &Detector{
 		ec2Client:  ec2Client,
 		tfParser:   tfParser,
 		attributes: attributes,
+		registry:   NewDefaultRegistry(),
+	}
+
+	for _, opt := range opts {
+		opt(d)
 	}
+
+	return d
 }
 
 func (d *Detector) Detect(ctx context.Context, instanceIDs []string) ([]Result, error) {
@@ -58,50 +87,175 @@ func (d *Detector) DetectConcurrent(ctx context.Context, instanceIDs []string) (
 	return results, nil
 }
 
+// DetectBatch fetches all AWS instances with batched API calls and then
+// compares each against Terraform state. Instances whose lookup failed are
+// reported with StatusError, as Detect does.
+func (d *Detector) DetectBatch(ctx context.Context, instanceIDs []string) ([]Result, error) {
+	awsConfigs, err := d.ec2Client.GetInstances(ctx, instanceIDs)
+	var batchErr *aws.BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		batchErr = &aws.BatchError{Errors: make(map[string]error, len(instanceIDs))}
+		for _, instanceID := range instanceIDs {
+			batchErr.Errors[instanceID] = err
+		}
+	}
+
+	results := make([]Result, 0, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		if batchErr != nil && batchErr.Errors[instanceID] != nil {
+			results = append(results, d.compareInstance(instanceID, nil, batchErr.Errors[instanceID]))
+			continue
+		}
+
+		awsConfig, exists := awsConfigs[instanceID]
+		if !exists {
+			results = append(results, d.compareInstance(instanceID, nil,
+				fmt.Errorf("instance %s: %w", instanceID, aws.ErrInstanceNotFound)))
+			continue
+		}
+		results = append(results, d.compareInstance(instanceID, awsConfig, nil))
+	}
+
+	return results, nil
+}
+
 func (d *Detector) detectSingleInstance(ctx context.Context, instanceID string) Result {
+	awsConfig, err := d.ec2Client.GetInstance(ctx, instanceID)
+	return d.compareInstance(instanceID, awsConfig, err)
+}
+
+// compareInstance classifies an instance from the outcome of its AWS lookup
+// and compares it against Terraform state
+func (d *Detector) compareInstance(instanceID string, awsConfig map[string]interface{}, awsErr error) Result {
 	result := Result{
-		InstanceID: instanceID,
-		Drifts:     make([]AttributeDrift, 0),
+		InstanceID:     instanceID,
+		Drifts:         make([]AttributeDrift, 0),
+		SensitivePaths: d.sensitivePaths(nil),
 	}
 
-	// Get AWS configuration
-	awsConfig, err := d.ec2Client.GetInstance(ctx, instanceID)
-	if err != nil {
-		result.Error = fmt.Errorf("failed to get AWS instance: %w", err)
+	if awsErr != nil && !errors.Is(awsErr, aws.ErrInstanceNotFound) {
+		result.Status = StatusError
+		result.Error = fmt.Errorf("failed to get AWS instance: %w", awsErr)
 		return result
 	}
+	existsInAWS := awsErr == nil
+	if existsInAWS {
+		result.Tags = tagsFromConfig(awsConfig)
+	}
 
 	// Get Terraform configuration
 	tfConfig, err := d.tfParser.GetInstanceConfig(instanceID)
+	if errors.Is(err, terraform.ErrInstanceNotInState) {
+		if existsInAWS {
+			result.Status = StatusUnmanaged
+		} else {
+			result.Status = StatusNotInState
+		}
+		return result
+	}
 	if err != nil {
+		result.Status = StatusError
 		result.Error = fmt.Errorf("failed to get Terraform config: %w", err)
 		return result
 	}
 
+	// Resolve the Terraform address for reporting
+	address, err := d.tfParser.GetInstanceAddress(instanceID)
+	if err != nil {
+		result.Status = StatusError
+		result.Error = fmt.Errorf("failed to get Terraform address: %w", err)
+		return result
+	}
+	result.Address = address
+
+	sensitive, err := d.tfParser.GetSensitiveAttributes(instanceID)
+	if err != nil {
+		result.Status = StatusError
+		result.Error = fmt.Errorf("failed to get sensitive attributes: %w", err)
+		return result
+	}
+	result.SensitivePaths = d.sensitivePaths(sensitive)
+
+	if !existsInAWS {
+		result.Status = StatusDeleted
+		return result
+	}
+
 	// Compare attributes
 	for _, attr := range d.attributes {
 		drift := d.compareAttribute(attr, awsConfig, tfConfig)
 		if drift != nil {
+			drift.Sensitive = drift.Sensitive || isSensitive(attr, result.SensitivePaths)
 			result.Drifts = append(result.Drifts, *drift)
 			result.HasDrift = true
 		}
 	}
 
+	result.Status = StatusInSync
+	if result.HasDrift {
+		result.Status = StatusDrifted
+	}
+
 	return result
 }
 
 func (d *Detector) compareAttribute(attr string, awsConfig, tfConfig map[string]interface{}) *AttributeDrift {
-	awsValue := getNestedValue(awsConfig, attr)
-	tfValue := getNestedValue(tfConfig, attr)
-
-	if !valuesEqual(awsValue, tfValue) {
-		return &AttributeDrift{
-			Attribute:      attr,
-			AWSValue:       awsValue,
-			TerraformValue: tfValue,
-			Path:           attr,
+	schema := d.registry.schemaFor(attr)
+
+	awsValue := schema.NormalizeAWS(getNestedValue(awsConfig, attr))
+	tfValue := schema.NormalizeTerraform(getNestedValue(tfConfig, attr))
+
+	drift := schema.Compare(awsValue, tfValue)
+	if drift == nil {
+		return nil
+	}
+
+	drift.Attribute = attr
+	drift.Path = attr
+	drift.AWSValue = awsValue
+	drift.TerraformValue = tfValue
+	drift.Severity = schema.Severity
+	drift.Sensitive = schema.Sensitive
+	return drift
+}
+
+// sensitivePaths resolves the paths to redact for an instance: those
+// Terraform flags, those passed to WithSensitiveAttributes and attributes
+// whose schema is sensitive
+func (d *Detector) sensitivePaths(tfPaths []string) []string {
+	paths := make([]string, 0, len(tfPaths)+len(d.sensitive))
+	paths = append(paths, tfPaths...)
+	paths = append(paths, d.sensitive...)
+
+	for _, name := range d.registry.Names() {
+		if schema, _ := d.registry.Lookup(name); schema.Sensitive {
+			paths = append(paths, name)
 		}
 	}
+	return paths
+}
 
-	return nil
-}
\ No newline at end of file
+// isSensitive reports whether an attribute overlaps any sensitive path: the
+// attribute itself, a value nested inside it, or a parent of it
+func isSensitive(attr string, sensitivePaths []string) bool {
+	for _, path := range sensitivePaths {
+		if attr == path || strings.HasPrefix(path, attr+".") || strings.HasPrefix(attr, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsFromConfig extracts AWS tags as strings for reporting
+func tagsFromConfig(config map[string]interface{}) map[string]string {
+	tags, ok := config["tags"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(tags))
+	for key, value := range tags {
+		result[key] = fmt.Sprintf("%v", value)
+	}
+	return result
+}
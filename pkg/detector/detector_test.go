@@ -2,31 +2,66 @@ package detector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
 )
 
 // Mock EC2 Client for testing
 type mockEC2Client struct {
-	instances map[string]map[string]any
+	instances  map[string]map[string]any
+	batchCalls int
 }
 
 func (m *mockEC2Client) GetInstance(ctx context.Context, instanceID string) (map[string]any, error) {
 	config, exists := m.instances[instanceID]
 	if !exists {
-		return nil, nil
+		return nil, fmt.Errorf("instance %s: %w", instanceID, aws.ErrInstanceNotFound)
 	}
 	return config, nil
 }
 
+func (m *mockEC2Client) GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]any, error) {
+	m.batchCalls++
+	instances := make(map[string]map[string]any, len(instanceIDs))
+	for _, id := range instanceIDs {
+		if config, exists := m.instances[id]; exists {
+			instances[id] = config
+		}
+	}
+	return instances, nil
+}
+
+func (m *mockEC2Client) ListInstances(ctx context.Context, filter aws.InstanceFilter) ([]aws.InstanceSummary, error) {
+	summaries := make([]aws.InstanceSummary, 0, len(m.instances))
+	for id, config := range m.instances {
+		summary := aws.InstanceSummary{InstanceID: id, State: "running", Tags: tagsFromConfig(config)}
+		if len(filter.States) > 0 && !slices.Contains(filter.States, summary.State) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].InstanceID < summaries[j].InstanceID
+	})
+	return summaries, nil
+}
+
 // Mock Terraform Parser for testing
 type mockTerraformParser struct {
 	instances map[string]map[string]any
+	sensitive map[string][]string
 }
 
 func (m *mockTerraformParser) GetInstanceConfig(instanceID string) (map[string]any, error) {
 	config, exists := m.instances[instanceID]
 	if !exists {
-		return nil, nil
+		return nil, fmt.Errorf("%w: %s", terraform.ErrInstanceNotInState, instanceID)
 	}
 	return config, nil
 }
@@ -47,6 +82,20 @@ func (m *mockTerraformParser) GetInstanceIDs() ([]string, error) {
 	return ids, nil
 }
 
+func (m *mockTerraformParser) GetInstanceAddress(instanceID string) (string, error) {
+	if _, exists := m.instances[instanceID]; !exists {
+		return "", fmt.Errorf("%w: %s", terraform.ErrInstanceNotInState, instanceID)
+	}
+	return "aws_instance." + instanceID, nil
+}
+
+func (m *mockTerraformParser) GetSensitiveAttributes(instanceID string) ([]string, error) {
+	if _, exists := m.instances[instanceID]; !exists {
+		return nil, fmt.Errorf("%w: %s", terraform.ErrInstanceNotInState, instanceID)
+	}
+	return m.sensitive[instanceID], nil
+}
+
 // Tests
 func TestDetector_Detect_NoDrift(t *testing.T) {
 	ec2Client := &mockEC2Client{
@@ -81,6 +130,10 @@ func TestDetector_Detect_NoDrift(t *testing.T) {
 	if results[0].HasDrift {
 		t.Errorf("Expected no drift")
 	}
+
+	if results[0].Address != "aws_instance.i-test" {
+		t.Errorf("Expected address aws_instance.i-test, got %s", results[0].Address)
+	}
 }
 
 func TestDetector_Detect_WithDrift(t *testing.T) {
@@ -146,6 +199,200 @@ func TestDetector_DetectConcurrent(t *testing.T) {
 	}
 }
 
+func TestDetector_DetectBatch(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-test1": {"instance_type": "t3.medium"},
+			"i-test2": {"instance_type": "t3.large"},
+		},
+	}
+
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-test1": {"instance_type": "t3.medium"},
+			"i-test2": {"instance_type": "t3.medium"},
+			"i-test3": {"instance_type": "t3.small"},
+		},
+	}
+
+	detector := New(ec2Client, tfParser, []string{"instance_type"})
+	results, err := detector.DetectBatch(context.Background(),
+		[]string{"i-test1", "i-test2", "i-test3"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ec2Client.batchCalls != 1 {
+		t.Errorf("Expected 1 batch call, got %d", ec2Client.batchCalls)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if results[0].HasDrift {
+		t.Errorf("Expected no drift for i-test1")
+	}
+
+	if !results[1].HasDrift {
+		t.Errorf("Expected drift for i-test2")
+	}
+
+	if results[2].Status != StatusDeleted {
+		t.Errorf("Expected status %s for instance missing in AWS, got %s", StatusDeleted, results[2].Status)
+	}
+}
+
+// failingEC2Client returns the same error for every lookup
+type failingEC2Client struct {
+	err error
+}
+
+func (f *failingEC2Client) GetInstance(ctx context.Context, instanceID string) (map[string]any, error) {
+	return nil, f.err
+}
+
+func (f *failingEC2Client) GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]any, error) {
+	return nil, f.err
+}
+
+func (f *failingEC2Client) ListInstances(ctx context.Context, filter aws.InstanceFilter) ([]aws.InstanceSummary, error) {
+	return nil, f.err
+}
+
+func TestDetector_Statuses(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-sync":      {"instance_type": "t3.medium"},
+			"i-drift":     {"instance_type": "t3.large"},
+			"i-unmanaged": {"instance_type": "t3.small"},
+		},
+	}
+
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-sync":    {"instance_type": "t3.medium"},
+			"i-drift":   {"instance_type": "t3.medium"},
+			"i-deleted": {"instance_type": "t3.medium"},
+		},
+	}
+
+	detector := New(ec2Client, tfParser, []string{"instance_type"})
+	results, err := detector.Detect(context.Background(),
+		[]string{"i-sync", "i-drift", "i-deleted", "i-unmanaged", "i-typo"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []Status{StatusInSync, StatusDrifted, StatusDeleted, StatusUnmanaged, StatusNotInState}
+	for i, status := range expected {
+		if results[i].Status != status {
+			t.Errorf("%s: expected status %s, got %s", results[i].InstanceID, status, results[i].Status)
+		}
+		if results[i].Error != nil {
+			t.Errorf("%s: expected no error, got %v", results[i].InstanceID, results[i].Error)
+		}
+	}
+
+	summary := Summarize(results)
+	if summary.Total != 5 || summary.InSync != 1 || summary.Drifted != 1 ||
+		summary.Deleted != 1 || summary.Unmanaged != 1 || summary.NotInState != 1 || summary.Errors != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
+
+func TestDetector_AWSFailure(t *testing.T) {
+	apiErr := errors.New("throttled")
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-test": {"instance_type": "t3.medium"},
+		},
+	}
+
+	detector := New(&failingEC2Client{err: apiErr}, tfParser, []string{"instance_type"})
+	results, err := detector.Detect(context.Background(), []string{"i-test"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if results[0].Status != StatusError {
+		t.Errorf("Expected status %s, got %s", StatusError, results[0].Status)
+	}
+
+	if !errors.Is(results[0].Error, apiErr) {
+		t.Errorf("Expected wrapped API error, got %v", results[0].Error)
+	}
+}
+
+// partialEC2Client fails the batched lookup of the instances in failed
+type partialEC2Client struct {
+	mockEC2Client
+	failed map[string]error
+}
+
+func (p *partialEC2Client) GetInstances(ctx context.Context, instanceIDs []string) (map[string]map[string]any, error) {
+	instances, _ := p.mockEC2Client.GetInstances(ctx, instanceIDs)
+	for id := range p.failed {
+		delete(instances, id)
+	}
+	return instances, &aws.BatchError{Errors: p.failed}
+}
+
+func TestDetector_DetectBatchFailures(t *testing.T) {
+	apiErr := errors.New("throttled")
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-test1": {"instance_type": "t3.medium"},
+			"i-test2": {"instance_type": "t3.medium"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		client   aws.EC2Client
+		expected []Status
+	}{
+		{
+			name:     "every lookup fails",
+			client:   &failingEC2Client{err: apiErr},
+			expected: []Status{StatusError, StatusError},
+		},
+		{
+			name: "one page fails",
+			client: &partialEC2Client{
+				mockEC2Client: mockEC2Client{instances: map[string]map[string]any{
+					"i-test1": {"instance_type": "t3.medium"},
+					"i-test2": {"instance_type": "t3.medium"},
+				}},
+				failed: map[string]error{"i-test2": apiErr},
+			},
+			expected: []Status{StatusInSync, StatusError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := New(tt.client, tfParser, []string{"instance_type"})
+			results, err := detector.DetectBatch(context.Background(), []string{"i-test1", "i-test2"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for i, status := range tt.expected {
+				if results[i].Status != status {
+					t.Errorf("%s: expected status %s, got %s", results[i].InstanceID, status, results[i].Status)
+				}
+				if status == StatusError && !errors.Is(results[i].Error, apiErr) {
+					t.Errorf("%s: expected wrapped API error, got %v", results[i].InstanceID, results[i].Error)
+				}
+			}
+		})
+	}
+}
+
 func TestDetector_SecurityGroupsDrift(t *testing.T) {
 	ec2Client := &mockEC2Client{
 		instances: map[string]map[string]any{
@@ -171,7 +418,45 @@ func TestDetector_SecurityGroupsDrift(t *testing.T) {
 	}
 
 	if !results[0].HasDrift {
-		t.Errorf("Expected drift to be detected")
+		t.Fatalf("Expected drift to be detected")
+	}
+
+	drift := results[0].Drifts[0]
+	if len(drift.Added) != 1 || drift.Added[0] != "sg-456" {
+		t.Errorf("Expected sg-456 to be reported as added, got %v", drift.Added)
+	}
+
+	if len(drift.Removed) != 0 {
+		t.Errorf("Expected no removed members, got %v", drift.Removed)
+	}
+}
+
+func TestDetector_SecurityGroupsOrderInsensitive(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-test": {
+				"vpc_security_group_ids": []string{"sg-456", "sg-123"},
+			},
+		},
+	}
+
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-test": {
+				"vpc_security_group_ids": []any{"sg-123", "sg-456"},
+			},
+		},
+	}
+
+	detector := New(ec2Client, tfParser, []string{"vpc_security_group_ids"})
+	results, err := detector.Detect(context.Background(), []string{"i-test"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if results[0].HasDrift {
+		t.Errorf("Expected no drift for reordered security groups, got %v", results[0].Drifts)
 	}
 }
 
@@ -206,4 +491,4 @@ func TestDetector_TagsDrift(t *testing.T) {
 	if !results[0].HasDrift {
 		t.Errorf("Expected drift in tags")
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,50 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+// Discover lists the instances in the account that match the filter and
+// returns those that appear in none of the given Terraform states. Of the
+// options, only WithRegistry and WithSensitiveAttributes apply.
+func Discover(ctx context.Context, ec2Client aws.EC2Client, parsers []terraform.Parser, filter aws.InstanceFilter, opts ...Option) ([]Result, error) {
+	d := New(ec2Client, nil, nil, opts...)
+
+	managed := make(map[string]bool)
+	for _, parser := range parsers {
+		ids, err := parser.GetInstanceIDs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Terraform instance IDs: %w", err)
+		}
+		for _, id := range ids {
+			managed[id] = true
+		}
+	}
+
+	instances, err := ec2Client.ListInstances(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AWS instances: %w", err)
+	}
+
+	results := make([]Result, 0)
+	for _, instance := range instances {
+		if managed[instance.InstanceID] {
+			continue
+		}
+
+		results = append(results, Result{
+			InstanceID:     instance.InstanceID,
+			Status:         StatusUnmanaged,
+			Drifts:         make([]AttributeDrift, 0),
+			Tags:           instance.Tags,
+			LaunchTime:     instance.LaunchTime,
+			SensitivePaths: d.sensitivePaths(nil),
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package detector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/aws"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+func TestDiscover_AcrossStates(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-app":   {"tags": map[string]any{"Name": "app"}},
+			"i-db":    {"tags": map[string]any{"Name": "db"}},
+			"i-click": {"tags": map[string]any{"Name": "clickops"}},
+		},
+	}
+
+	appState := &mockTerraformParser{instances: map[string]map[string]any{"i-app": {}}}
+	dbState := &mockTerraformParser{instances: map[string]map[string]any{"i-db": {}}}
+
+	results, err := Discover(context.Background(), ec2Client,
+		[]terraform.Parser{appState, dbState}, aws.InstanceFilter{},
+		WithSensitiveAttributes("tags.Owner"))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 unmanaged instance, got %d", len(results))
+	}
+
+	if results[0].InstanceID != "i-click" || results[0].Status != StatusUnmanaged {
+		t.Errorf("Expected i-click to be unmanaged, got %s (%s)", results[0].InstanceID, results[0].Status)
+	}
+
+	if results[0].Tags["Name"] != "clickops" {
+		t.Errorf("Expected Name tag clickops, got %q", results[0].Tags["Name"])
+	}
+
+	if !slices.Contains(results[0].SensitivePaths, "tags.Owner") {
+		t.Errorf("Expected sensitive paths to include tags.Owner, got %v", results[0].SensitivePaths)
+	}
+}
+
+func TestDiscover_Filter(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-click": {},
+		},
+	}
+
+	results, err := Discover(context.Background(), ec2Client, nil,
+		aws.InstanceFilter{States: []string{"stopped"}})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 0 {
+		t.Errorf("Expected filtered listing to return no instances, got %d", len(results))
+	}
+}
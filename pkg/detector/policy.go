@@ -0,0 +1,110 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPolicy fails on any drift and on any instance that could not be
+// checked
+const DefaultPolicy = "drift,error"
+
+// Policy decides which results fail a run. It is parsed from a
+// comma-separated list of rules:
+//
+//	drift           any drifted, deleted or unmanaged instance
+//	error           any instance that errored or is in neither state nor AWS
+//	severity>=LEVEL any drifted attribute, deleted or unmanaged instance at
+//	                or above LEVEL (see StatusSeverity)
+//	none            never fail
+type Policy struct {
+	Drift       bool
+	Errors      bool
+	MinSeverity Severity // Empty when no severity rule is set
+}
+
+// Verdict is the outcome of applying a policy to results
+type Verdict struct {
+	Drift  bool // A drift rule matched
+	Errors bool // The error rule matched
+}
+
+// Failed reports whether any rule matched
+func (v Verdict) Failed() bool {
+	return v.Drift || v.Errors
+}
+
+// ParsePolicy parses a comma-separated list of policy rules
+func ParsePolicy(rules string) (Policy, error) {
+	var policy Policy
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.ToLower(strings.Join(strings.Fields(rule), ""))
+
+		switch {
+		case rule == "" || rule == "none":
+			continue
+		case rule == "drift":
+			policy.Drift = true
+		case rule == "error" || rule == "errors":
+			policy.Errors = true
+		case strings.HasPrefix(rule, "severity>="):
+			severity := Severity(strings.TrimPrefix(rule, "severity>="))
+			if severity.Rank() == 0 {
+				return Policy{}, fmt.Errorf("unknown severity %q in fail-on rule: use low, medium, high or critical", severity)
+			}
+			policy.MinSeverity = severity
+		default:
+			return Policy{}, fmt.Errorf("unknown fail-on rule %q: use drift, error, severity>=<low|medium|high|critical> or none", rule)
+		}
+	}
+
+	return policy, nil
+}
+
+// Evaluate applies the policy to results
+func (p Policy) Evaluate(results []Result) Verdict {
+	var verdict Verdict
+
+	for _, result := range results {
+		switch result.Status {
+		case StatusDrifted, StatusDeleted, StatusUnmanaged:
+			if p.Drift {
+				verdict.Drift = true
+			}
+		case StatusError, StatusNotInState:
+			if p.Errors {
+				verdict.Errors = true
+			}
+		}
+
+		if p.MinSeverity == "" {
+			continue
+		}
+		if severity, ok := StatusSeverity(result.Status); ok && severity.Rank() >= p.MinSeverity.Rank() {
+			verdict.Drift = true
+		}
+		for _, drift := range result.Drifts {
+			if drift.Severity.Rank() >= p.MinSeverity.Rank() {
+				verdict.Drift = true
+			}
+		}
+	}
+
+	return verdict
+}
+
+// StatusSeverity returns the severity of a result status that has no
+// attribute drifts of its own. An instance deleted outside Terraform is
+// critical and an unmanaged instance is high; other statuses are judged by
+// their drifts, so ok is false.
+func StatusSeverity(status Status) (Severity, bool) {
+	switch status {
+	case StatusDeleted:
+		return SeverityCritical, true
+	case StatusUnmanaged:
+		return SeverityHigh, true
+	default:
+		return "", false
+	}
+}
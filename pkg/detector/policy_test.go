@@ -0,0 +1,77 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		rules    string
+		expected Policy
+		err      string
+	}{
+		{"drift,error", Policy{Drift: true, Errors: true}, ""},
+		{" severity >= HIGH ", Policy{MinSeverity: SeverityHigh}, ""},
+		{"error, severity>=critical", Policy{Errors: true, MinSeverity: SeverityCritical}, ""},
+		{"none", Policy{}, ""},
+		{"severity>=urgent", Policy{}, `unknown severity "urgent"`},
+		{"warnings", Policy{}, `unknown fail-on rule "warnings"`},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParsePolicy(tt.rules)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: expected error containing %q, got %v", tt.rules, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", tt.rules, err)
+		}
+		if policy != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.rules, tt.expected, policy)
+		}
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	mediumDrift := Result{Status: StatusDrifted, HasDrift: true, Drifts: []AttributeDrift{{Attribute: "instance_type", Severity: SeverityMedium}}}
+	criticalDrift := Result{Status: StatusDrifted, HasDrift: true, Drifts: []AttributeDrift{{Attribute: "vpc_security_group_ids", Severity: SeverityCritical}}}
+	failed := Result{Status: StatusError}
+	deleted := Result{Status: StatusDeleted}
+	unmanaged := Result{Status: StatusUnmanaged}
+	inSync := Result{Status: StatusInSync}
+
+	tests := []struct {
+		name     string
+		rules    string
+		results  []Result
+		expected Verdict
+	}{
+		{"in sync", DefaultPolicy, []Result{inSync}, Verdict{}},
+		{"drift", DefaultPolicy, []Result{inSync, mediumDrift}, Verdict{Drift: true}},
+		{"deleted counts as drift", "drift", []Result{deleted}, Verdict{Drift: true}},
+		{"drift and errors", DefaultPolicy, []Result{mediumDrift, failed}, Verdict{Drift: true, Errors: true}},
+		{"errors only ignores drift", "error", []Result{mediumDrift}, Verdict{}},
+		{"severity below threshold", "severity>=high", []Result{mediumDrift, inSync}, Verdict{}},
+		{"deleted is critical", "severity>=critical", []Result{deleted}, Verdict{Drift: true}},
+		{"unmanaged is high", "severity>=high", []Result{unmanaged}, Verdict{Drift: true}},
+		{"unmanaged below critical", "severity>=critical", []Result{unmanaged}, Verdict{}},
+		{"severity at threshold", "severity>=high", []Result{criticalDrift}, Verdict{Drift: true}},
+		{"none", "none", []Result{criticalDrift, failed}, Verdict{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParsePolicy(tt.rules)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if verdict := policy.Evaluate(tt.results); verdict != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, verdict)
+			}
+		})
+	}
+}
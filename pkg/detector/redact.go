@@ -0,0 +1,105 @@
+package detector
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// RedactedValue stands in for a sensitive value in reports. Two values only
+// share a RedactedValue when they are equal, so readers can still tell that
+// something changed without seeing the secret.
+type RedactedValue string
+
+// redactionKey keys the redaction hashes. It is random per process so that
+// hashes cannot be brute-forced or compared across runs.
+var redactionKey = newRedactionKey()
+
+func newRedactionKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate redaction key: %v", err))
+	}
+	return key
+}
+
+// redactValue replaces a value with a keyed hash of its JSON encoding
+func redactValue(val any) any {
+	if val == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", val))
+	}
+
+	mac := hmac.New(sha256.New, redactionKey)
+	mac.Write(data)
+	return RedactedValue("(sensitive) hmac:" + hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+// Redact returns a copy of the results with the values of sensitive drifts
+// and tags replaced by RedactedValue hashes. The reporters and notifiers
+// call it before writing anything; each has an UnsafeShowSensitive field
+// that skips it.
+func Redact(results []Result) []Result {
+	redacted := make([]Result, len(results))
+
+	for i, result := range results {
+		redacted[i] = result
+		redacted[i].Tags = redactTags(result.Tags, result.SensitivePaths)
+		if len(result.Drifts) == 0 {
+			continue
+		}
+
+		drifts := make([]AttributeDrift, len(result.Drifts))
+		for j, drift := range result.Drifts {
+			if drift.Sensitive {
+				drift.AWSValue = redactValue(drift.AWSValue)
+				drift.TerraformValue = redactValue(drift.TerraformValue)
+				drift.Added = redactMembers(drift.Added)
+				drift.Removed = redactMembers(drift.Removed)
+			}
+			drifts[j] = drift
+		}
+		redacted[i].Drifts = drifts
+	}
+
+	return redacted
+}
+
+// redactTags returns a copy of the tags with sensitive values replaced:
+// every value when tags itself is sensitive, otherwise those named by a
+// tags.<key> path
+func redactTags(tags map[string]string, paths []string) map[string]string {
+	if tags == nil {
+		return nil
+	}
+
+	all := slices.Contains(paths, "tags")
+	redacted := make(map[string]string, len(tags))
+	for key, value := range tags {
+		if all || slices.Contains(paths, "tags."+key) {
+			value = string(redactValue(value).(RedactedValue))
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+func redactMembers(members []string) []string {
+	if members == nil {
+		return nil
+	}
+
+	redacted := make([]string, len(members))
+	for i, member := range members {
+		redacted[i] = string(redactValue(member).(RedactedValue))
+	}
+	return redacted
+}
@@ -0,0 +1,153 @@
+package detector
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetector_SensitiveDrift(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-test": {
+				"instance_type": "t3.large",
+				"user_data":     "password=new",
+				"tags":          map[string]any{"Name": "app", "Secret": "new"},
+			},
+		},
+	}
+
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-test": {
+				"instance_type": "t3.medium",
+				"user_data":     "password=old",
+				"tags":          map[string]any{"Name": "app", "Secret": "old"},
+			},
+		},
+		sensitive: map[string][]string{
+			"i-test": {"tags.Secret"},
+		},
+	}
+
+	detector := New(ec2Client, tfParser, []string{"instance_type", "user_data", "tags"},
+		WithSensitiveAttributes("user_data"))
+	results, err := detector.Detect(context.Background(), []string{"i-test"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sensitive := make(map[string]bool)
+	for _, drift := range results[0].Drifts {
+		sensitive[drift.Attribute] = drift.Sensitive
+	}
+
+	expected := map[string]bool{"instance_type": false, "user_data": true, "tags": true}
+	for attr, want := range expected {
+		if got, ok := sensitive[attr]; !ok || got != want {
+			t.Errorf("Expected %s sensitive=%v, got %v (reported: %v)", attr, want, got, ok)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	results := []Result{{
+		InstanceID: "i-test",
+		Status:     StatusDrifted,
+		HasDrift:   true,
+		Drifts: []AttributeDrift{
+			{Attribute: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium"},
+			{Attribute: "user_data", AWSValue: "password=new", TerraformValue: "password=old", Sensitive: true},
+			{Attribute: "security_groups", Added: []string{"sg-secret"}, Sensitive: true},
+		},
+	}}
+
+	redacted := Redact(results)
+
+	if results[0].Drifts[1].AWSValue != "password=new" {
+		t.Errorf("Expected Redact to leave the original results untouched")
+	}
+
+	drifts := redacted[0].Drifts
+	if drifts[0].AWSValue != "t3.large" {
+		t.Errorf("Expected non-sensitive value to be kept, got %v", drifts[0].AWSValue)
+	}
+
+	awsValue, ok := drifts[1].AWSValue.(RedactedValue)
+	if !ok || !strings.HasPrefix(string(awsValue), "(sensitive)") {
+		t.Fatalf("Expected redacted AWS value, got %v", drifts[1].AWSValue)
+	}
+	if awsValue == drifts[1].TerraformValue {
+		t.Errorf("Expected different values to redact differently")
+	}
+	if strings.Contains(string(awsValue), "password") {
+		t.Errorf("Redacted value leaks the secret: %s", awsValue)
+	}
+
+	if len(drifts[2].Added) != 1 || drifts[2].Added[0] == "sg-secret" {
+		t.Errorf("Expected added members to be redacted, got %v", drifts[2].Added)
+	}
+
+	again := Redact(results)
+	if again[0].Drifts[1].AWSValue != awsValue {
+		t.Errorf("Expected redaction to be stable within a run")
+	}
+}
+
+func TestRedact_Tags(t *testing.T) {
+	tags := map[string]string{"Name": "app", "Secret": "hunter2"}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		redacted []string
+	}{
+		{"no sensitive paths", nil, nil},
+		{"single tag", []string{"tags.Secret"}, []string{"Secret"}},
+		{"whole map", []string{"tags"}, []string{"Name", "Secret"}},
+		{"other attribute", []string{"user_data"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Redact([]Result{{InstanceID: "i-test", Status: StatusInSync, Tags: tags, SensitivePaths: tt.paths}})
+
+			for key, value := range tags {
+				got := results[0].Tags[key]
+				if want := slices.Contains(tt.redacted, key); want != (got != value) {
+					t.Errorf("Expected tag %s redacted=%v, got %q", key, want, got)
+				}
+			}
+			if tags["Secret"] != "hunter2" {
+				t.Errorf("Expected Redact to leave the original tags untouched")
+			}
+		})
+	}
+}
+
+func TestDetector_SensitiveTagsOnEveryStatus(t *testing.T) {
+	ec2Client := &mockEC2Client{
+		instances: map[string]map[string]any{
+			"i-sync":      {"instance_type": "t3.micro", "tags": map[string]any{"Secret": "hunter2"}},
+			"i-unmanaged": {"instance_type": "t3.micro", "tags": map[string]any{"Secret": "hunter2"}},
+		},
+	}
+	tfParser := &mockTerraformParser{
+		instances: map[string]map[string]any{
+			"i-sync": {"instance_type": "t3.micro"},
+		},
+	}
+
+	detector := New(ec2Client, tfParser, []string{"instance_type"}, WithSensitiveAttributes("tags.Secret"))
+	results, err := detector.Detect(context.Background(), []string{"i-sync", "i-unmanaged"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, result := range Redact(results) {
+		if secret := result.Tags["Secret"]; secret == "" || secret == "hunter2" {
+			t.Errorf("%s (%s): expected the Secret tag to be redacted", result.InstanceID, result.Status)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// AttributeType describes the shape of an attribute value
+type AttributeType string
+
+const (
+	TypeString AttributeType = "string"
+	TypeBool   AttributeType = "bool"
+	TypeInt    AttributeType = "int"
+	TypeList   AttributeType = "list"
+	TypeSet    AttributeType = "set"
+	TypeMap    AttributeType = "map"
+	TypeBlock  AttributeType = "block"
+)
+
+// Severity ranks how serious drift in an attribute is
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders severities from least to most serious
+var severityRanks = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// Rank returns the position of a severity in the low < medium < high <
+// critical ordering, or 0 for an unknown severity
+func (s Severity) Rank() int {
+	return severityRanks[s]
+}
+
+// NormalizeFunc converts a raw attribute value into its canonical form
+type NormalizeFunc func(val any) any
+
+// CompareFunc compares canonical AWS and Terraform values and returns nil
+// when they match. The detector fills in the attribute name and path.
+type CompareFunc func(awsValue, tfValue any) *AttributeDrift
+
+// AttributeSchema declares how a single attribute is normalized and compared
+type AttributeSchema struct {
+	Name               string
+	Type               AttributeType
+	Description        string
+	NormalizeAWS       NormalizeFunc
+	NormalizeTerraform NormalizeFunc
+	Compare            CompareFunc
+	Severity           Severity // Defaults to SeverityMedium
+	Sensitive          bool     // Values are redacted in reports
+}
+
+// Registry holds the attribute schemas known to the detector
+type Registry struct {
+	mu         sync.RWMutex
+	attributes map[string]AttributeSchema
+}
+
+// NewRegistry creates an empty attribute registry
+func NewRegistry() *Registry {
+	return &Registry{
+		attributes: make(map[string]AttributeSchema),
+	}
+}
+
+// Register adds an attribute schema to the registry
+func (r *Registry) Register(schema AttributeSchema) error {
+	if schema.Name == "" {
+		return fmt.Errorf("attribute schema must have a name")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.attributes[schema.Name]; exists {
+		return fmt.Errorf("attribute %s is already registered", schema.Name)
+	}
+
+	r.attributes[schema.Name] = schema
+	return nil
+}
+
+// MustRegister adds an attribute schema and panics if registration fails
+func (r *Registry) MustRegister(schema AttributeSchema) {
+	if err := r.Register(schema); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the schema registered for an attribute
+func (r *Registry) Lookup(name string) (AttributeSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.attributes[name]
+	return schema, ok
+}
+
+// Names returns the registered attribute names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.attributes))
+	for name := range r.attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaFor returns the registered schema for an attribute, falling back to
+// a generic schema for attributes that are not registered
+func (r *Registry) schemaFor(name string) AttributeSchema {
+	schema, ok := r.Lookup(name)
+	if !ok {
+		schema = AttributeSchema{Name: name}
+	}
+
+	if schema.NormalizeAWS == nil {
+		schema.NormalizeAWS = identity
+	}
+	if schema.NormalizeTerraform == nil {
+		schema.NormalizeTerraform = identity
+	}
+
+	// Version 3 state stores scalars as strings; type those the schema
+	// declares as bool or int before normalizing
+	if schema.Type == TypeBool || schema.Type == TypeInt {
+		normalize, attrType := schema.NormalizeTerraform, schema.Type
+		schema.NormalizeTerraform = func(val any) any {
+			return normalize(parseScalar(attrType, val))
+		}
+	}
+	if schema.Compare == nil {
+		schema.Compare = compareValues
+	}
+	if schema.Severity == "" {
+		schema.Severity = SeverityMedium
+	}
+	return schema
+}
+
+func identity(val any) any {
+	return val
+}
+
+// parseScalar converts a string holding a bool or integer to the value JSON
+// decoding gives; other values are returned unchanged
+func parseScalar(attrType AttributeType, val any) any {
+	s, ok := val.(string)
+	if !ok {
+		return val
+	}
+
+	switch attrType {
+	case TypeBool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case TypeInt:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return float64(i)
+		}
+	}
+	return val
+}
@@ -0,0 +1,184 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(AttributeSchema{Name: "instance_type", Type: TypeString}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := registry.Register(AttributeSchema{Name: "instance_type", Type: TypeString}); err == nil {
+		t.Errorf("Expected error for duplicate registration")
+	}
+
+	if err := registry.Register(AttributeSchema{Type: TypeString}); err == nil {
+		t.Errorf("Expected error for unnamed schema")
+	}
+
+	if _, ok := registry.Lookup("instance_type"); !ok {
+		t.Errorf("Expected instance_type to be registered")
+	}
+}
+
+func TestRegistry_SchemaForUnregistered(t *testing.T) {
+	schema := NewRegistry().schemaFor("custom")
+
+	if schema.Compare("a", "a") != nil {
+		t.Errorf("Expected equal values to match")
+	}
+
+	if schema.Compare("a", "b") == nil {
+		t.Errorf("Expected different values to drift")
+	}
+
+	if schema.Severity != SeverityMedium {
+		t.Errorf("Expected default severity medium, got %s", schema.Severity)
+	}
+}
+
+func TestDefaultRegistry_Attributes(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	tests := []struct {
+		name     string
+		attr     string
+		aws      any
+		tf       any
+		expected bool // true when drift is expected
+	}{
+		{"tags missing vs empty", "tags", nil, map[string]any{}, false},
+		{"tags differ", "tags", map[string]any{"Env": "prod"}, map[string]any{"Env": "dev"}, true},
+		{"key name unset vs empty", "key_name", nil, "", false},
+		{"monitoring disabled vs false", "monitoring", "disabled", false, false},
+		{"monitoring enabled vs false", "monitoring", "enabled", false, true},
+		{"monitoring pending vs true", "monitoring", "pending", true, false},
+		{"monitoring string bool", "monitoring", "enabled", "true", false},
+		{"iam profile arn vs name", "iam_instance_profile", "arn:aws:iam::123456789012:instance-profile/web", "web", false},
+		{"iam profile arn with path", "iam_instance_profile", "arn:aws:iam::123456789012:instance-profile/team/app/web", "web", false},
+		{"iam profile different name", "iam_instance_profile", "arn:aws:iam::123456789012:instance-profile/web", "api", true},
+		{"iam profile unset", "iam_instance_profile", nil, "", false},
+		{"security groups reordered", "vpc_security_group_ids", []string{"sg-2", "sg-1"}, []any{"sg-1", "sg-2"}, false},
+		{
+			"root block device common fields match",
+			"root_block_device",
+			map[string]any{"device_name": "/dev/xvda", "delete_on_termination": true},
+			[]any{map[string]any{"device_name": "/dev/xvda", "delete_on_termination": true, "volume_size": int64(8)}},
+			false,
+		},
+		{
+			"root block device string is not a bool",
+			"root_block_device",
+			map[string]any{"delete_on_termination": true},
+			[]any{map[string]any{"delete_on_termination": "true"}},
+			true,
+		},
+		{
+			"root block device differs",
+			"root_block_device",
+			map[string]any{"device_name": "/dev/xvda", "delete_on_termination": false},
+			[]any{map[string]any{"device_name": "/dev/xvda", "delete_on_termination": true}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := registry.schemaFor(tt.attr)
+			drift := schema.Compare(schema.NormalizeAWS(tt.aws), schema.NormalizeTerraform(tt.tf))
+			if (drift != nil) != tt.expected {
+				t.Errorf("Expected drift %v, got %v", tt.expected, drift != nil)
+			}
+		})
+	}
+}
+
+func TestRegistry_SchemaForTypesStrings(t *testing.T) {
+	registry := NewRegistry()
+	registry.MustRegister(AttributeSchema{Name: "cpu_core_count", Type: TypeInt})
+	registry.MustRegister(AttributeSchema{Name: "ebs_optimized", Type: TypeBool})
+	registry.MustRegister(AttributeSchema{Name: "key_name", Type: TypeString})
+
+	tests := []struct {
+		attr     string
+		value    any
+		expected any
+	}{
+		{"cpu_core_count", "4", float64(4)},
+		{"cpu_core_count", "four", "four"},
+		{"ebs_optimized", "true", true},
+		{"key_name", "123", "123"},
+	}
+
+	for _, tt := range tests {
+		if got := registry.schemaFor(tt.attr).NormalizeTerraform(tt.value); got != tt.expected {
+			t.Errorf("%s: expected %#v, got %#v", tt.attr, tt.expected, got)
+		}
+	}
+}
+
+// TestDefaultRegistry_LegacyStateMatchesV4 checks that the same instance
+// stored as version 3 and version 4 state compares identically
+func TestDefaultRegistry_LegacyStateMatchesV4(t *testing.T) {
+	v3 := `{"version": 3, "modules": [{"path": ["root"], "resources": {"aws_instance.web": {
+	  "type": "aws_instance",
+	  "primary": {"id": "i-web", "attributes": {
+	    "id": "i-web",
+	    "monitoring": "true",
+	    "key_name": "123",
+	    "tags.%": "1",
+	    "tags.Build": "42",
+	    "root_block_device.#": "1",
+	    "root_block_device.0.delete_on_termination": "true",
+	    "root_block_device.0.volume_size": "8"
+	  }}
+	}}}]}`
+	v4 := `{"version": 4, "resources": [{"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+	  {"attributes": {
+	    "id": "i-web",
+	    "monitoring": true,
+	    "key_name": "123",
+	    "tags": {"Build": "42"},
+	    "root_block_device": [{"delete_on_termination": true, "volume_size": 8}]
+	  }}
+	]}]}`
+
+	dir := t.TempDir()
+	configs := make([]map[string]any, 0, 2)
+	for i, content := range []string{v3, v4} {
+		path := filepath.Join(dir, fmt.Sprintf("v%d.tfstate", i+3))
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write state file: %v", err)
+		}
+		config, err := terraform.NewStateParser(path).GetInstanceConfig("i-web")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		configs = append(configs, config)
+	}
+
+	registry := NewDefaultRegistry()
+	for _, attr := range []string{"monitoring", "key_name", "tags", "root_block_device"} {
+		schema := registry.schemaFor(attr)
+		legacy := schema.NormalizeTerraform(configs[0][attr])
+		current := schema.NormalizeTerraform(configs[1][attr])
+
+		if drift := schema.Compare(legacy, current); drift != nil {
+			t.Errorf("%s: expected version 3 %#v to match version 4 %#v", attr, legacy, current)
+		}
+	}
+
+	aws := map[string]any{"delete_on_termination": false, "volume_size": float64(8)}
+	schema := registry.schemaFor("root_block_device")
+	if schema.Compare(aws, schema.NormalizeTerraform(configs[0]["root_block_device"])) == nil {
+		t.Errorf("Expected version 3 root block device to drift from AWS")
+	}
+}
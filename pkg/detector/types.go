@@ -1,15 +1,87 @@
 package detector
 
+import "time"
+
+// Status classifies the outcome of checking a single instance
+type Status string
+
+const (
+	// StatusInSync means every checked attribute matches Terraform
+	StatusInSync Status = "in_sync"
+	// StatusDrifted means at least one attribute differs from Terraform
+	StatusDrifted Status = "drifted"
+	// StatusDeleted means the instance is in Terraform state but no longer exists in AWS
+	StatusDeleted Status = "deleted_outside_terraform"
+	// StatusNotInState means the instance exists neither in Terraform state nor in AWS
+	StatusNotInState Status = "not_in_state"
+	// StatusUnmanaged means the instance exists in AWS but not in Terraform state
+	StatusUnmanaged Status = "unmanaged"
+	// StatusError means the instance could not be checked
+	StatusError Status = "error"
+)
+
 type Result struct {
 	InstanceID string
+	Address    string // Full Terraform resource address
+	Status     Status
 	HasDrift   bool
 	Drifts     []AttributeDrift
 	Error      error
+	Tags       map[string]string // AWS tags, when the instance exists in AWS
+	LaunchTime time.Time         // Set for discovered instances
+	// SensitivePaths lists the attribute paths, such as user_data or
+	// tags.Secret, whose values Redact hides
+	SensitivePaths []string
 }
 
 type AttributeDrift struct {
 	Attribute      string
 	AWSValue       any
 	TerraformValue any
-	Path           string // For nested attributes
-}
\ No newline at end of file
+	Path           string   // For nested attributes
+	Added          []string // Set members present in AWS but not in Terraform
+	Removed        []string // Set members present in Terraform but not in AWS
+	Severity       Severity
+	Sensitive      bool // Values must be redacted when reported
+}
+
+// IsSensitive reports whether a path, such as tags.Name, overlaps one of
+// the result's sensitive paths
+func (r Result) IsSensitive(path string) bool {
+	return isSensitive(path, r.SensitivePaths)
+}
+
+// Summary counts results by status
+type Summary struct {
+	Total      int
+	InSync     int
+	Drifted    int
+	Deleted    int
+	NotInState int
+	Unmanaged  int
+	Errors     int
+}
+
+// Summarize counts results by status
+func Summarize(results []Result) Summary {
+	summary := Summary{Total: len(results)}
+
+	for _, result := range results {
+		switch result.Status {
+		case StatusInSync:
+			summary.InSync++
+		case StatusDrifted:
+			summary.Drifted++
+		case StatusDeleted:
+			summary.Deleted++
+		case StatusNotInState:
+			summary.NotInState++
+		case StatusUnmanaged:
+			summary.Unmanaged++
+		default:
+			summary.Errors++
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+	defaultBackoff     = 500 * time.Millisecond
+)
+
+// Notifier sends drift results to an external system
+type Notifier interface {
+	Notify(ctx context.Context, results []detector.Result) error
+}
+
+// RetryPolicy controls how failed deliveries are retried. Network errors,
+// 429 and 5xx responses are retried with exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration // Delay before the second attempt, doubled after each retry
+}
+
+// DefaultRetryPolicy makes three attempts starting with a 500ms delay
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: defaultMaxAttempts, Backoff: defaultBackoff}
+}
+
+// NeedsAttention reports whether any result drifted, was deleted outside
+// Terraform or could not be checked. Unmanaged and not-in-state results are
+// discovery output and do not count.
+func NeedsAttention(results []detector.Result) bool {
+	for _, result := range results {
+		switch result.Status {
+		case detector.StatusDrifted, detector.StatusDeleted, detector.StatusError:
+			return true
+		}
+	}
+	return false
+}
+
+// sender delivers request bodies over HTTP with retries
+type sender struct {
+	client *http.Client
+	retry  RetryPolicy
+}
+
+func newSender(client *http.Client, retry RetryPolicy) sender {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return sender{client: client, retry: retry}
+}
+
+// post sends body to url, retrying transient failures
+func (s sender) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	backoff := s.retry.Backoff
+
+	var lastErr error
+	for attempt := 1; attempt <= s.retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("gave up after %d attempt(s): %w", attempt-1, lastErr)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := s.attempt(ctx, url, body, headers)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			return err
+		}
+	}
+
+	return fmt.Errorf("gave up after %d attempt(s): %w", s.retry.MaxAttempts, lastErr)
+}
+
+// attempt makes a single request and reports whether a failure is worth
+// retrying
+func (s sender) attempt(ctx context.Context, url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, err
+}
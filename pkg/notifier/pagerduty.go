@@ -0,0 +1,341 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier opens an incident per drifted instance attribute at or
+// above MinSeverity and resolves it once a later run finds the attribute
+// back in sync, or the instance deleted or no longer managed. Incidents are
+// keyed by DedupKey, so repeated runs update the same incident instead of
+// opening new ones. Instances that fail to be checked, or are left out of a
+// run, keep their incidents open.
+type PagerDutyNotifier struct {
+	RoutingKey string
+	EventsURL  string
+
+	// MinSeverity is the lowest attribute severity that pages; defaults to
+	// critical (security groups and IAM instance profile)
+	MinSeverity detector.Severity
+
+	// Registry supplies attribute severities for resolving incidents
+	Registry *detector.Registry
+
+	// Attributes limits which attributes are tracked, typically the
+	// attributes checked by the run; all registered attributes when empty
+	Attributes []string
+
+	Source string // Shown as the incident source; defaults to ec2-drift-detector
+
+	// StateFile records the incidents this notifier opened, so later runs
+	// only resolve those. Without it every tracked attribute of every
+	// checked instance is resolved on each run, one event each, which can
+	// exceed the routing key's rate limit for large fleets.
+	StateFile string
+
+	// UnsafeShowSensitive sends sensitive drift values in the incident's
+	// custom details
+	UnsafeShowSensitive bool
+
+	Client *http.Client
+	Retry  RetryPolicy
+}
+
+func NewPagerDutyNotifier(routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		RoutingKey:  routingKey,
+		EventsURL:   PagerDutyEventsURL,
+		MinSeverity: detector.SeverityCritical,
+		Registry:    detector.NewDefaultRegistry(),
+		Source:      "ec2-drift-detector",
+		Client:      &http.Client{Timeout: defaultTimeout},
+		Retry:       DefaultRetryPolicy(),
+	}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+
+	incident openIncident
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group"`
+	Class         string         `json:"class"`
+	CustomDetails map[string]any `json:"custom_details"`
+}
+
+// DedupKey derives the stable PagerDuty dedup key for an instance attribute
+func DedupKey(instanceID, attribute string) string {
+	sum := sha256.Sum256([]byte(instanceID + "\x00" + attribute))
+	return "ec2-drift-" + hex.EncodeToString(sum[:])[:32]
+}
+
+// Notify sends a trigger event for every paging drift and resolve events
+// for incidents that no longer apply. Delivery continues past failures and
+// all errors are returned together, but stops once ctx is done.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, results []detector.Result) error {
+	if !n.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	var open map[string]openIncident
+	if n.StateFile != "" {
+		var err error
+		if open, err = loadIncidents(n.StateFile); err != nil {
+			return err
+		}
+	}
+
+	events := n.events(results, open)
+	send := newSender(n.Client, n.Retry)
+	url := n.EventsURL
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+
+	var errs []error
+	for i, event := range events {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("stopped after %d of %d PagerDuty events: %w", i, len(events), err))
+			break
+		}
+
+		body, err := json.Marshal(event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to encode PagerDuty event: %w", err))
+			continue
+		}
+		sendErr := send.post(ctx, url, body, nil)
+		if sendErr != nil {
+			errs = append(errs, fmt.Errorf("failed to send PagerDuty %s for %s: %w", event.EventAction, event.DedupKey, sendErr))
+		}
+
+		// Failed triggers are still recorded; resolving them later is harmless
+		if open != nil {
+			if event.EventAction == "trigger" {
+				open[event.DedupKey] = event.incident
+			} else if sendErr == nil {
+				delete(open, event.DedupKey)
+			}
+		}
+	}
+
+	if open != nil {
+		if err := saveIncidents(n.StateFile, open); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// events builds the events for a run. open holds the incidents recorded in
+// the state file, or is nil when there is none.
+func (n *PagerDutyNotifier) events(results []detector.Result, open map[string]openIncident) []pagerDutyEvent {
+	tracked := n.trackedAttributes()
+	events := make([]pagerDutyEvent, 0)
+
+	for _, result := range results {
+		// An error says nothing about the instance's attributes. Deleted and
+		// unmanaged instances have nothing left to compare, so their
+		// incidents are resolved.
+		if result.Status == detector.StatusError {
+			continue
+		}
+
+		triggered := make(map[string]bool, len(result.Drifts))
+		for _, drift := range result.Drifts {
+			if drift.Severity.Rank() < n.minSeverity().Rank() {
+				continue
+			}
+			triggered[drift.Attribute] = true
+			events = append(events, n.triggerEvent(result, drift))
+		}
+
+		candidates := tracked
+		if open != nil {
+			candidates = openAttributes(open, result.InstanceID)
+		}
+		for _, attr := range candidates {
+			if !triggered[attr] {
+				events = append(events, pagerDutyEvent{
+					RoutingKey:  n.RoutingKey,
+					EventAction: "resolve",
+					DedupKey:    DedupKey(result.InstanceID, attr),
+				})
+			}
+		}
+	}
+
+	return events
+}
+
+func (n *PagerDutyNotifier) triggerEvent(result detector.Result, drift detector.AttributeDrift) pagerDutyEvent {
+	subject := result.InstanceID
+	if result.Address != "" {
+		subject = fmt.Sprintf("%s (%s)", result.Address, result.InstanceID)
+	}
+
+	source := n.Source
+	if source == "" {
+		source = "ec2-drift-detector"
+	}
+
+	return pagerDutyEvent{
+		RoutingKey:  n.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    DedupKey(result.InstanceID, drift.Attribute),
+		incident:    openIncident{InstanceID: result.InstanceID, Attribute: drift.Attribute},
+		Payload: &pagerDutyPayload{
+			Summary:   fmt.Sprintf("%s drifted from Terraform on %s", drift.Attribute, subject),
+			Source:    source,
+			Severity:  pagerDutySeverity(drift.Severity),
+			Component: result.InstanceID,
+			Group:     result.Address,
+			Class:     drift.Attribute,
+			CustomDetails: map[string]any{
+				"instance_id":     result.InstanceID,
+				"address":         result.Address,
+				"attribute":       drift.Attribute,
+				"aws_value":       drift.AWSValue,
+				"terraform_value": drift.TerraformValue,
+				"added":           drift.Added,
+				"removed":         drift.Removed,
+			},
+		},
+	}
+}
+
+// trackedAttributes returns the attributes whose incidents this notifier
+// manages, i.e. those registered at or above MinSeverity
+func (n *PagerDutyNotifier) trackedAttributes() []string {
+	registry := n.Registry
+	if registry == nil {
+		registry = detector.NewDefaultRegistry()
+	}
+
+	candidates := n.Attributes
+	if len(candidates) == 0 {
+		candidates = registry.Names()
+	}
+
+	tracked := make([]string, 0, len(candidates))
+	for _, attr := range candidates {
+		schema, ok := registry.Lookup(attr)
+		if ok && schema.Severity.Rank() >= n.minSeverity().Rank() {
+			tracked = append(tracked, attr)
+		}
+	}
+	return tracked
+}
+
+func (n *PagerDutyNotifier) minSeverity() detector.Severity {
+	if n.MinSeverity == "" {
+		return detector.SeverityCritical
+	}
+	return n.MinSeverity
+}
+
+// pagerDutySeverity maps drift severity to a PagerDuty event severity
+func pagerDutySeverity(severity detector.Severity) string {
+	switch severity {
+	case detector.SeverityCritical:
+		return "critical"
+	case detector.SeverityHigh:
+		return "error"
+	case detector.SeverityLow:
+		return "info"
+	default:
+		return "warning"
+	}
+}
+
+// openIncident is an incident recorded in the state file
+type openIncident struct {
+	InstanceID string `json:"instance_id"`
+	Attribute  string `json:"attribute"`
+}
+
+// incidentState is the state file layout, keyed by dedup key
+type incidentState struct {
+	OpenIncidents map[string]openIncident `json:"open_incidents"`
+}
+
+// loadIncidents reads the open incidents; a missing file means none
+func loadIncidents(path string) (map[string]openIncident, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]openIncident), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PagerDuty state: %w", err)
+	}
+
+	var state incidentState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse PagerDuty state %s: %w", path, err)
+	}
+	if state.OpenIncidents == nil {
+		state.OpenIncidents = make(map[string]openIncident)
+	}
+	return state.OpenIncidents, nil
+}
+
+// saveIncidents replaces the state file atomically
+func saveIncidents(path string, open map[string]openIncident) error {
+	data, err := json.MarshalIndent(incidentState{OpenIncidents: open}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".pagerduty-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write PagerDuty state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write PagerDuty state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write PagerDuty state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write PagerDuty state: %w", err)
+	}
+	return nil
+}
+
+// openAttributes returns the attributes of an instance's open incidents
+func openAttributes(open map[string]openIncident, instanceID string) []string {
+	attributes := make([]string, 0)
+	for _, incident := range open {
+		if incident.InstanceID == instanceID {
+			attributes = append(attributes, incident.Attribute)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
+}
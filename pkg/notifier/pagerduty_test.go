@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+// pagerDutyStandIn records events posted to a local Events API stand-in
+type pagerDutyStandIn struct {
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+func (p *pagerDutyStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event pagerDutyEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	p.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (p *pagerDutyStandIn) actions() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	actions := make(map[string]string)
+	for _, event := range p.events {
+		actions[event.DedupKey] = event.EventAction
+	}
+	return actions
+}
+
+func TestPagerDutyNotifier_TriggerThenResolve(t *testing.T) {
+	standIn := &pagerDutyStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier("routing-key")
+	notifier.EventsURL = server.URL
+	notifier.Attributes = []string{"instance_type", "vpc_security_group_ids", "iam_instance_profile"}
+
+	drifted := []detector.Result{{
+		InstanceID: "i-web",
+		Address:    "aws_instance.web",
+		Status:     detector.StatusDrifted,
+		HasDrift:   true,
+		Drifts: []detector.AttributeDrift{
+			{Attribute: "vpc_security_group_ids", Added: []string{"sg-open"}, Severity: detector.SeverityCritical},
+			{Attribute: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium", Severity: detector.SeverityMedium},
+		},
+	}}
+	if err := notifier.Notify(context.Background(), drifted); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sgKey := DedupKey("i-web", "vpc_security_group_ids")
+	profileKey := DedupKey("i-web", "iam_instance_profile")
+
+	actions := standIn.actions()
+	if len(actions) != 2 || actions[sgKey] != "trigger" || actions[profileKey] != "resolve" {
+		t.Fatalf("Expected trigger for security groups and resolve for the profile, got %v", actions)
+	}
+	if actions[DedupKey("i-web", "instance_type")] != "" {
+		t.Errorf("Expected medium severity drift not to page")
+	}
+
+	trigger := standIn.events[0]
+	if trigger.RoutingKey != "routing-key" || trigger.Payload == nil || trigger.Payload.Severity != "critical" {
+		t.Errorf("Unexpected trigger event %+v", trigger)
+	}
+
+	standIn.events = nil
+	inSync := []detector.Result{{InstanceID: "i-web", Address: "aws_instance.web", Status: detector.StatusInSync}}
+	if err := notifier.Notify(context.Background(), inSync); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if standIn.actions()[sgKey] != "resolve" {
+		t.Errorf("Expected security group incident to resolve, got %v", standIn.actions())
+	}
+}
+
+func TestPagerDutyNotifier_ResolvesGoneInstances(t *testing.T) {
+	standIn := &pagerDutyStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier("routing-key")
+	notifier.EventsURL = server.URL
+	notifier.Attributes = []string{"vpc_security_group_ids"}
+
+	results := []detector.Result{
+		{InstanceID: "i-broken", Status: detector.StatusError},
+		{InstanceID: "i-gone", Status: detector.StatusDeleted},
+		{InstanceID: "i-stray", Status: detector.StatusUnmanaged},
+	}
+	if err := notifier.Notify(context.Background(), results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	actions := standIn.actions()
+	if _, ok := actions[DedupKey("i-broken", "vpc_security_group_ids")]; ok {
+		t.Errorf("Expected no events for instances that could not be checked")
+	}
+	if actions[DedupKey("i-gone", "vpc_security_group_ids")] != "resolve" ||
+		actions[DedupKey("i-stray", "vpc_security_group_ids")] != "resolve" {
+		t.Errorf("Expected deleted and unmanaged instances to resolve, got %v", actions)
+	}
+}
+
+func TestPagerDutyNotifier_StateFileResolvesOnlyOpenIncidents(t *testing.T) {
+	standIn := &pagerDutyStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier("routing-key")
+	notifier.EventsURL = server.URL
+	notifier.StateFile = filepath.Join(t.TempDir(), "pagerduty.json")
+
+	fleet := func(webStatus detector.Status, drifts ...detector.AttributeDrift) []detector.Result {
+		results := []detector.Result{{InstanceID: "i-web", Status: webStatus, Drifts: drifts, HasDrift: len(drifts) > 0}}
+		for i := 0; i < 50; i++ {
+			results = append(results, detector.Result{InstanceID: fmt.Sprintf("i-%02d", i), Status: detector.StatusInSync})
+		}
+		return results
+	}
+	sgDrift := detector.AttributeDrift{Attribute: "vpc_security_group_ids", Added: []string{"sg-open"}, Severity: detector.SeverityCritical}
+	sgKey := DedupKey("i-web", "vpc_security_group_ids")
+
+	runs := []struct {
+		name    string
+		results []detector.Result
+		events  map[string]string
+	}{
+		{"trigger", fleet(detector.StatusDrifted, sgDrift), map[string]string{sgKey: "trigger"}},
+		{"still drifted", fleet(detector.StatusDrifted, sgDrift), map[string]string{sgKey: "trigger"}},
+		{"error keeps incident", fleet(detector.StatusError), map[string]string{}},
+		{"back in sync", fleet(detector.StatusInSync), map[string]string{sgKey: "resolve"}},
+		{"nothing open", fleet(detector.StatusInSync), map[string]string{}},
+	}
+
+	for _, run := range runs {
+		standIn.events = nil
+		if err := notifier.Notify(context.Background(), run.results); err != nil {
+			t.Fatalf("%s: expected no error, got %v", run.name, err)
+		}
+		if actions := standIn.actions(); fmt.Sprint(actions) != fmt.Sprint(run.events) || len(standIn.events) != len(run.events) {
+			t.Errorf("%s: expected events %v, got %v", run.name, run.events, standIn.events)
+		}
+	}
+}
+
+func TestPagerDutyNotifier_StopsWhenContextDone(t *testing.T) {
+	standIn := &pagerDutyStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier("routing-key")
+	notifier.EventsURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := []detector.Result{{InstanceID: "i-web", Status: detector.StatusInSync}}
+	if err := notifier.Notify(ctx, results); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(standIn.events) != 0 {
+		t.Errorf("Expected no events after the context was done, got %d", len(standIn.events))
+	}
+}
+
+func TestDedupKey_Stable(t *testing.T) {
+	if DedupKey("i-1", "tags") != DedupKey("i-1", "tags") {
+		t.Errorf("Expected dedup key to be stable")
+	}
+	if DedupKey("i-1", "tags") == DedupKey("i-1t", "ags") {
+		t.Errorf("Expected instance and attribute to be kept apart")
+	}
+}
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+const (
+	// maxSlackInstances keeps messages well below Slack's 50 block limit
+	maxSlackInstances = 10
+	// maxSlackText is Slack's limit for section block text
+	maxSlackText = 3000
+)
+
+// SlackNotifier posts a Block Kit message to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+
+	// Always sends a notification even when every instance is in sync
+	Always bool
+
+	// UnsafeShowSensitive posts sensitive drift values and a sensitive Name
+	// tag to the channel
+	UnsafeShowSensitive bool
+
+	Client *http.Client
+	Retry  RetryPolicy
+}
+
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: defaultTimeout},
+		Retry:      DefaultRetryPolicy(),
+	}
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Notify posts the results unless there is nothing to report
+func (n *SlackNotifier) Notify(ctx context.Context, results []detector.Result) error {
+	if !n.Always && !NeedsAttention(results) {
+		return nil
+	}
+
+	if !n.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	body, err := json.Marshal(newSlackMessage(results))
+	if err != nil {
+		return fmt.Errorf("failed to build Slack message: %w", err)
+	}
+
+	if err := newSender(n.Client, n.Retry).post(ctx, n.WebhookURL, body, nil); err != nil {
+		return fmt.Errorf("failed to send Slack message: %w", err)
+	}
+	return nil
+}
+
+func newSlackMessage(results []detector.Result) slackMessage {
+	summary := detector.Summarize(results)
+
+	title := "EC2 instances match Terraform"
+	if NeedsAttention(results) {
+		title = "EC2 Terraform drift detected"
+	}
+
+	message := slackMessage{
+		Text: fmt.Sprintf("%s: %d drifted, %d deleted, %d unmanaged, %d errors of %d instances",
+			title, summary.Drifted, summary.Deleted, summary.Unmanaged, summary.Errors, summary.Total),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+			{Type: "section", Fields: []slackText{
+				slackField("Drifted", summary.Drifted),
+				slackField("Deleted outside Terraform", summary.Deleted),
+				slackField("Not in state", summary.NotInState),
+				slackField("Unmanaged", summary.Unmanaged),
+				slackField("Errors", summary.Errors),
+				slackField("In sync", summary.InSync),
+			}},
+		},
+	}
+
+	shown := 0
+	for _, result := range results {
+		if result.Status == detector.StatusInSync {
+			continue
+		}
+		if shown == maxSlackInstances {
+			remaining := summary.Total - summary.InSync - shown
+			message.Blocks = append(message.Blocks, slackBlock{
+				Type:     "context",
+				Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("…and %d more instance(s)", remaining)}},
+			})
+			break
+		}
+
+		if shown == 0 {
+			message.Blocks = append(message.Blocks, slackBlock{Type: "divider"})
+		}
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: slackInstanceText(result)},
+		})
+		shown++
+	}
+
+	return message
+}
+
+func slackField(label string, count int) slackText {
+	return slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%d", label, count)}
+}
+
+// slackInstanceText describes one instance in Slack mrkdwn
+func slackInstanceText(result detector.Result) string {
+	var b strings.Builder
+
+	if result.Address != "" {
+		fmt.Fprintf(&b, "*%s* (`%s`)", slackEscape(result.Address), slackEscape(result.InstanceID))
+	} else {
+		fmt.Fprintf(&b, "*%s*", slackEscape(result.InstanceID))
+	}
+	if name := result.Tags["Name"]; name != "" {
+		fmt.Fprintf(&b, " %s", slackEscape(name))
+	}
+	fmt.Fprintf(&b, ": %s", strings.ReplaceAll(string(result.Status), "_", " "))
+
+	if result.Error != nil {
+		fmt.Fprintf(&b, "\n%s", slackEscape(result.Error.Error()))
+	}
+	for _, drift := range result.Drifts {
+		fmt.Fprintf(&b, "\n• `%s` is `%s` in AWS, `%s` in Terraform",
+			slackEscape(drift.Attribute), slackEscape(compactValue(drift.AWSValue)), slackEscape(compactValue(drift.TerraformValue)))
+	}
+
+	text := b.String()
+	if len(text) > maxSlackText {
+		text = strings.ToValidUTF8(text[:maxSlackText-len("…")], "") + "…"
+	}
+	return text
+}
+
+// slackEscape escapes the characters Slack treats as markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// compactValue formats a value on a single line
+func compactValue(val any) string {
+	if val == nil {
+		return "<nil>"
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
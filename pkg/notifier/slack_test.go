@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var message slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("Expected JSON body, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	if err := NewSlackNotifier(server.URL).Notify(context.Background(), driftedResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(message.Blocks) != 5 || message.Blocks[0].Type != "header" {
+		t.Fatalf("Expected header, summary, divider and 2 instance blocks, got %+v", message.Blocks)
+	}
+
+	drifted := message.Blocks[3].Text.Text
+	if !strings.Contains(drifted, "*aws_instance.web* (`i-drifted`) web: drifted") ||
+		!strings.Contains(drifted, "`instance_type` is `\"t3.large\"` in AWS") {
+		t.Errorf("Unexpected instance text: %s", drifted)
+	}
+	if strings.Contains(drifted, "password") {
+		t.Errorf("Expected sensitive values to be redacted: %s", drifted)
+	}
+	if !strings.Contains(message.Blocks[4].Text.Text, "throttled") {
+		t.Errorf("Expected error message, got %s", message.Blocks[4].Text.Text)
+	}
+}
+
+func TestSlackMessage_LimitsInstances(t *testing.T) {
+	results := make([]detector.Result, 0, 15)
+	for i := 0; i < 15; i++ {
+		results = append(results, detector.Result{InstanceID: fmt.Sprintf("i-%02d", i), Status: detector.StatusDeleted})
+	}
+
+	message := newSlackMessage(results)
+
+	last := message.Blocks[len(message.Blocks)-1]
+	if last.Type != "context" || !strings.Contains(last.Elements[0].Text, "5 more") {
+		t.Errorf("Expected a note about 5 more instances, got %+v", last)
+	}
+	if len(message.Blocks) > 50 {
+		t.Errorf("Expected at most 50 blocks, got %d", len(message.Blocks))
+	}
+}
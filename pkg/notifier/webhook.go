@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/reporter"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook body
+const SignatureHeader = "X-Signature"
+
+// WebhookNotifier posts the JSON report (see reporter.JSONReporter) to a
+// URL. When a secret is set the body is signed so receivers can verify it.
+type WebhookNotifier struct {
+	URL      string
+	Secret   []byte
+	Metadata reporter.Metadata
+
+	// Always sends a notification even when every instance is in sync
+	Always bool
+
+	// UnsafeShowSensitive sends sensitive drift values, set members and tags
+	// in the payload
+	UnsafeShowSensitive bool
+
+	Client *http.Client
+	Retry  RetryPolicy
+}
+
+func NewWebhookNotifier(url string, secret []byte) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: defaultTimeout},
+		Retry:  DefaultRetryPolicy(),
+	}
+}
+
+// Notify posts the results unless there is nothing to report
+func (n *WebhookNotifier) Notify(ctx context.Context, results []detector.Result) error {
+	if !n.Always && !NeedsAttention(results) {
+		return nil
+	}
+
+	var body bytes.Buffer
+	payload := reporter.NewJSONReporter(&body, n.Metadata)
+	payload.UnsafeShowSensitive = n.UnsafeShowSensitive
+	if err := payload.Report(results); err != nil {
+		return fmt.Errorf("failed to build webhook payload: %w", err)
+	}
+
+	headers := make(map[string]string)
+	if len(n.Secret) > 0 {
+		headers[SignatureHeader] = Sign(n.Secret, body.Bytes())
+	}
+
+	if err := newSender(n.Client, n.Retry).post(ctx, n.URL, body.Bytes(), headers); err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a body, in the form
+// sha256=<hex HMAC-SHA256 of body>
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a signature header value matches the body
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func driftedResults() []detector.Result {
+	return []detector.Result{
+		{
+			InstanceID: "i-drifted",
+			Address:    "aws_instance.web",
+			Status:     detector.StatusDrifted,
+			HasDrift:   true,
+			Drifts: []detector.AttributeDrift{
+				{Attribute: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium"},
+				{Attribute: "user_data", AWSValue: "password=new", TerraformValue: "password=old", Sensitive: true},
+			},
+			Tags: map[string]string{"Name": "web"},
+		},
+		{InstanceID: "i-broken", Status: detector.StatusError, Error: errors.New("throttled")},
+	}
+}
+
+func inSyncResults() []detector.Result {
+	return []detector.Result{{InstanceID: "i-sync", Status: detector.StatusInSync}}
+}
+
+func fastRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+}
+
+func TestWebhookNotifier_SignsBody(t *testing.T) {
+	secret := []byte("s3cret")
+
+	var verified bool
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified = Verify(secret, body, r.Header.Get(SignatureHeader))
+		json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, secret).Notify(context.Background(), driftedResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !verified {
+		t.Errorf("Expected a valid %s header", SignatureHeader)
+	}
+	if payload["schema_version"] != "1" {
+		t.Errorf("Expected the versioned JSON report as payload, got %v", payload)
+	}
+}
+
+func TestWebhookNotifier_OnlyFiresOnDrift(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, nil)
+	if err := notifier.Notify(context.Background(), inSyncResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Expected no notification when everything is in sync")
+	}
+
+	notifier.Always = true
+	if err := notifier.Notify(context.Background(), inSyncResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a notification with Always set, got %d", calls.Load())
+	}
+}
+
+func TestNeedsAttention(t *testing.T) {
+	tests := []struct {
+		status   detector.Status
+		expected bool
+	}{
+		{detector.StatusInSync, false},
+		{detector.StatusDrifted, true},
+		{detector.StatusDeleted, true},
+		{detector.StatusError, true},
+		{detector.StatusUnmanaged, false},
+		{detector.StatusNotInState, false},
+	}
+
+	for _, tt := range tests {
+		results := []detector.Result{{InstanceID: "i-1", Status: tt.status}}
+		if got := NeedsAttention(results); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.status, tt.expected, got)
+		}
+	}
+}
+
+func TestWebhookNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"recovers after server error", []int{500, 503, 200}, 3, false},
+		{"retries rate limiting", []int{429, 200}, 2, false},
+		{"gives up after max attempts", []int{500, 500, 500, 500}, 3, true},
+		{"does not retry client error", []int{400, 200}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			notifier := NewWebhookNotifier(server.URL, nil)
+			notifier.Retry = fastRetry()
+
+			err := notifier.Notify(context.Background(), driftedResults())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, nil)
+	notifier.Client.Timeout = 20 * time.Millisecond
+	notifier.Retry = RetryPolicy{MaxAttempts: 1}
+
+	if err := notifier.Notify(context.Background(), driftedResults()); err == nil {
+		t.Errorf("Expected timeout error")
+	}
+}
+
+func TestNotifiers_RedactSensitiveTags(t *testing.T) {
+	results := driftedResults()
+	results[0].Tags = map[string]string{"Name": "classified-name", "Secret": "hunter2"}
+	results[0].SensitivePaths = []string{"tags"}
+
+	tests := []struct {
+		name string
+		new  func(url string) Notifier
+	}{
+		{"slack", func(url string) Notifier { return NewSlackNotifier(url) }},
+		{"webhook", func(url string) Notifier { return NewWebhookNotifier(url, []byte("s3cret")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			if err := tt.new(server.URL).Notify(context.Background(), results); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, secret := range []string{"hunter2", "classified-name"} {
+				if bytes.Contains(body, []byte(secret)) {
+					t.Errorf("Expected %q to be redacted, got %s", secret, body)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_UnsafeShowSensitive(t *testing.T) {
+	results := driftedResults()
+	results[0].Tags = map[string]string{"Secret": "hunter2"}
+	results[0].SensitivePaths = []string{"tags"}
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, nil)
+	notifier.UnsafeShowSensitive = true
+	if err := notifier.Notify(context.Background(), results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !bytes.Contains(body, []byte("hunter2")) {
+		t.Errorf("Expected the sensitive tag in the payload, got %s", body)
+	}
+}
@@ -0,0 +1,32 @@
+(function () {
+  var status = document.getElementById("filter-status");
+  var attribute = document.getElementById("filter-attribute");
+  var tag = document.getElementById("filter-tag");
+  var count = document.getElementById("filter-count");
+  var instances = document.querySelectorAll("article.instance");
+
+  function has(list, value) {
+    return value === "" || list.indexOf(value) !== -1;
+  }
+
+  function apply() {
+    var shown = 0;
+    instances.forEach(function (el) {
+      var attributes = (el.dataset.attributes || "").split(" ");
+      var tags = (el.dataset.tags || "").split("\n");
+      var visible = (status.value === "" || el.dataset.status === status.value) &&
+        has(attributes, attribute.value) &&
+        has(tags, tag.value);
+      el.classList.toggle("hidden", !visible);
+      if (visible) {
+        shown++;
+      }
+    });
+    count.textContent = shown + " of " + instances.length + " instances";
+  }
+
+  [status, attribute, tag].forEach(function (el) {
+    el.addEventListener("change", apply);
+  });
+  apply();
+})();
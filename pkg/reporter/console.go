@@ -1,66 +1,120 @@
 package reporter
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
 )
 
-type ConsoleReporter struct{}
+type ConsoleReporter struct {
+	w io.Writer
 
-func NewConsoleReporter() *ConsoleReporter {
-	return &ConsoleReporter{}
+	// UnsafeShowSensitive prints sensitive drift values and tags verbatim
+	UnsafeShowSensitive bool
 }
 
-// Report prints drift results to console
-func (r *ConsoleReporter) Report(results []detector.Result) {
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("EC2 TERRAFORM DRIFT DETECTION REPORT")
-	fmt.Println(strings.Repeat("=", 80))
+func NewConsoleReporter(w io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{w: w}
+}
+
+// Report writes human-readable drift results
+func (r *ConsoleReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
 
-	totalDrift := 0
-	totalErrors := 0
+	// Render into a buffer so a failing writer is reported once
+	var b bytes.Buffer
+
+	fmt.Fprintln(&b, "\n"+strings.Repeat("=", 80))
+	fmt.Fprintln(&b, "EC2 TERRAFORM DRIFT DETECTION REPORT")
+	fmt.Fprintln(&b, strings.Repeat("=", 80))
 
 	for _, result := range results {
-		fmt.Printf("\nInstance: %s\n", result.InstanceID)
-		fmt.Println(strings.Repeat("-", 80))
+		fmt.Fprintf(&b, "\nInstance: %s\n", result.InstanceID)
+		if result.Address != "" {
+			fmt.Fprintf(&b, "Address:  %s\n", result.Address)
+		}
+		fmt.Fprintln(&b, strings.Repeat("-", 80))
+
+		switch result.Status {
+		case detector.StatusDeleted:
+			fmt.Fprintln(&b, "Status: DELETED OUTSIDE TERRAFORM")
+			fmt.Fprintln(&b, statusDescriptions[detector.StatusDeleted])
+			continue
+		case detector.StatusNotInState:
+			fmt.Fprintln(&b, "Status: NOT IN STATE")
+			fmt.Fprintln(&b, statusDescriptions[detector.StatusNotInState])
+			continue
+		case detector.StatusUnmanaged:
+			fmt.Fprintln(&b, "Status: UNMANAGED")
+			fmt.Fprintln(&b, statusDescriptions[detector.StatusUnmanaged])
+			if !result.LaunchTime.IsZero() {
+				fmt.Fprintf(&b, "Launched: %s\n", result.LaunchTime.Format(time.RFC3339))
+			}
+			if len(result.Tags) > 0 {
+				fmt.Fprintf(&b, "Tags:     %s\n", formatTags(result.Tags))
+			}
+			continue
+		}
 
 		if result.Error != nil {
-			fmt.Printf("Error: %v\n", result.Error)
-			totalErrors++
+			fmt.Fprintf(&b, "Error: %v\n", result.Error)
 			continue
 		}
 
 		if result.HasDrift {
-			fmt.Printf("Drift Detected: YES (%d attribute(s))\n\n", len(result.Drifts))
-			totalDrift++
+			fmt.Fprintf(&b, "Drift Detected: YES (%d attribute(s))\n\n", len(result.Drifts))
 
 			for i, drift := range result.Drifts {
-				fmt.Printf("  %d. Attribute: %s\n", i+1, drift.Attribute)
-				fmt.Printf("     AWS Value:       %s\n", formatValue(drift.AWSValue))
-				fmt.Printf("     Terraform Value: %s\n", formatValue(drift.TerraformValue))
+				if drift.Sensitive {
+					fmt.Fprintf(&b, "  %d. Attribute: %s (sensitive)\n", i+1, drift.Attribute)
+				} else {
+					fmt.Fprintf(&b, "  %d. Attribute: %s\n", i+1, drift.Attribute)
+				}
+				fmt.Fprintf(&b, "     AWS Value:       %s\n", formatValue(drift.AWSValue))
+				fmt.Fprintf(&b, "     Terraform Value: %s\n", formatValue(drift.TerraformValue))
+				if len(drift.Added) > 0 {
+					fmt.Fprintf(&b, "     Added in AWS:    %s\n", formatValue(drift.Added))
+				}
+				if len(drift.Removed) > 0 {
+					fmt.Fprintf(&b, "     Removed in AWS:  %s\n", formatValue(drift.Removed))
+				}
 
 				if i < len(result.Drifts)-1 {
-					fmt.Println()
+					fmt.Fprintln(&b)
 				}
 			}
 		} else {
-			fmt.Println("Drift Detected: NO")
-			fmt.Println("All checked attributes match between AWS and Terraform")
+			fmt.Fprintln(&b, "Drift Detected: NO")
+			fmt.Fprintln(&b, "All checked attributes match between AWS and Terraform")
 		}
 	}
 
 	// Summary
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("SUMMARY")
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("Total Instances Checked: %d\n", len(results))
-	fmt.Printf("Instances with Drift:    %d\n", totalDrift)
-	fmt.Printf("Instances with Errors:   %d\n", totalErrors)
-	fmt.Printf("Instances in Sync:       %d\n", len(results)-totalDrift-totalErrors)
-	fmt.Println(strings.Repeat("=", 80) + "\n")
+	summary := detector.Summarize(results)
+	fmt.Fprintln(&b, "\n"+strings.Repeat("=", 80))
+	fmt.Fprintln(&b, "SUMMARY")
+	fmt.Fprintln(&b, strings.Repeat("=", 80))
+	fmt.Fprintf(&b, "Total Instances Checked:     %d\n", summary.Total)
+	fmt.Fprintf(&b, "Instances with Drift:        %d\n", summary.Drifted)
+	fmt.Fprintf(&b, "Deleted Outside Terraform:   %d\n", summary.Deleted)
+	fmt.Fprintf(&b, "Not in Terraform State:      %d\n", summary.NotInState)
+	fmt.Fprintf(&b, "Unmanaged Instances:         %d\n", summary.Unmanaged)
+	fmt.Fprintf(&b, "Instances with Errors:       %d\n", summary.Errors)
+	fmt.Fprintf(&b, "Instances in Sync:           %d\n", summary.InSync)
+	fmt.Fprintln(&b, strings.Repeat("=", 80)+"\n")
+
+	if _, err := r.w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write console report: %w", err)
+	}
+	return nil
 }
 
 // formatValue formats a value for display
@@ -91,6 +145,21 @@ func formatValue(val any) string {
 	}
 }
 
+// formatTags formats tags as sorted key=value pairs
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", key, tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
 // quoteStrings adds quotes around each string in a slice
 func quoteStrings(strs []string) []string {
 	quoted := make([]string, len(strs))
@@ -98,4 +167,4 @@ func quoteStrings(strs []string) []string {
 		quoted[i] = fmt.Sprintf(`"%s"`, s)
 	}
 	return quoted
-}
\ No newline at end of file
+}
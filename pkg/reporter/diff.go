@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"reflect"
+	"sort"
+)
+
+// mapEntry is one key of a side-by-side map comparison
+type mapEntry struct {
+	Key            string
+	AWSValue       any
+	TerraformValue any
+	InAWS          bool
+	InTerraform    bool
+}
+
+// Changed reports whether the key differs between AWS and Terraform
+func (e mapEntry) Changed() bool {
+	return e.InAWS != e.InTerraform || !reflect.DeepEqual(e.AWSValue, e.TerraformValue)
+}
+
+// diffMaps lines up the keys of two map values, such as tags, in sorted
+// order. It returns false when either value is not a map.
+func diffMaps(awsValue, tfValue any) ([]mapEntry, bool) {
+	awsMap, ok := asMap(awsValue)
+	if !ok {
+		return nil, false
+	}
+	tfMap, ok := asMap(tfValue)
+	if !ok {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(awsMap)+len(tfMap))
+	for key := range awsMap {
+		keys = append(keys, key)
+	}
+	for key := range tfMap {
+		if _, ok := awsMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]mapEntry, len(keys))
+	for i, key := range keys {
+		awsItem, inAWS := awsMap[key]
+		tfItem, inTerraform := tfMap[key]
+		entries[i] = mapEntry{
+			Key:            key,
+			AWSValue:       awsItem,
+			TerraformValue: tfItem,
+			InAWS:          inAWS,
+			InTerraform:    inTerraform,
+		}
+	}
+	return entries, true
+}
+
+func asMap(val any) (map[string]any, bool) {
+	switch v := val.(type) {
+	case map[string]any:
+		return v, true
+	case map[string]string:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[key] = item
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,258 @@
+package reporter
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+//go:embed assets
+var htmlAssets embed.FS
+
+var htmlTemplate = template.Must(template.ParseFS(htmlAssets, "assets/report.html.tmpl"))
+
+// HTMLReporter writes a single self-contained HTML file with summary charts,
+// filtering by status, attribute and tag, and side-by-side diffs
+type HTMLReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// UnsafeShowSensitive embeds sensitive drift values and tags in the page
+	UnsafeShowSensitive bool
+}
+
+func NewHTMLReporter(w io.Writer, meta Metadata) *HTMLReporter {
+	return &HTMLReporter{w: w, Metadata: meta}
+}
+
+type htmlReport struct {
+	GeneratedAt string
+	StateFile   string
+	Total       int
+	Statuses    []htmlCount
+	Attributes  []htmlCount
+	Tags        []string
+	Instances   []htmlInstance
+	CSS         template.CSS
+	JS          template.JS
+}
+
+// htmlCount is one bar of a chart
+type htmlCount struct {
+	Key     string
+	Label   string
+	Count   int
+	Percent int
+}
+
+type htmlInstance struct {
+	InstanceID    string
+	Address       string
+	Status        detector.Status
+	StatusLabel   string
+	Message       string
+	Tags          []string
+	TagList       string // Newline-separated key=value pairs for filtering
+	AttributeList string // Space-separated drifted attributes for filtering
+	Drifts        []htmlDrift
+}
+
+type htmlDrift struct {
+	Attribute string
+	Severity  detector.Severity
+	Sensitive bool
+	AWS       string
+	Terraform string
+	Added     []string
+	Removed   []string
+	Entries   []htmlMapEntry // Set for map values, shown key by key
+}
+
+type htmlMapEntry struct {
+	Key       string
+	AWS       string
+	Terraform string
+	Changed   bool
+}
+
+// Report writes the results as an HTML document
+func (r *HTMLReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	report, err := r.newReport(results)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	if _, err := r.w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+func (r *HTMLReporter) newReport(results []detector.Result) (htmlReport, error) {
+	css, err := htmlAssets.ReadFile("assets/report.css")
+	if err != nil {
+		return htmlReport{}, fmt.Errorf("failed to read HTML report styles: %w", err)
+	}
+	js, err := htmlAssets.ReadFile("assets/report.js")
+	if err != nil {
+		return htmlReport{}, fmt.Errorf("failed to read HTML report script: %w", err)
+	}
+
+	generatedAt := r.Metadata.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	report := htmlReport{
+		GeneratedAt: generatedAt.UTC().Format(time.RFC1123),
+		StateFile:   r.Metadata.StateFile,
+		Total:       len(results),
+		Instances:   make([]htmlInstance, 0, len(results)),
+		CSS:         template.CSS(css),
+		JS:          template.JS(js),
+	}
+
+	statusCounts := make(map[detector.Status]int)
+	attributeCounts := make(map[string]int)
+	tags := make(map[string]bool)
+
+	for _, result := range results {
+		instance := newHTMLInstance(result)
+		report.Instances = append(report.Instances, instance)
+
+		statusCounts[result.Status]++
+		for _, drift := range result.Drifts {
+			attributeCounts[drift.Attribute]++
+		}
+		for _, tag := range instance.Tags {
+			tags[tag] = true
+		}
+	}
+
+	for _, s := range statusOrder {
+		report.Statuses = append(report.Statuses, htmlCount{
+			Key:     string(s.status),
+			Label:   s.label,
+			Count:   statusCounts[s.status],
+			Percent: percent(statusCounts[s.status], len(results)),
+		})
+	}
+
+	maxDrifts := 0
+	for _, count := range attributeCounts {
+		maxDrifts = max(maxDrifts, count)
+	}
+	for _, attr := range sortedKeys(attributeCounts) {
+		report.Attributes = append(report.Attributes, htmlCount{
+			Label:   attr,
+			Count:   attributeCounts[attr],
+			Percent: percent(attributeCounts[attr], maxDrifts),
+		})
+	}
+
+	for tag := range tags {
+		report.Tags = append(report.Tags, tag)
+	}
+	sort.Strings(report.Tags)
+
+	return report, nil
+}
+
+func newHTMLInstance(result detector.Result) htmlInstance {
+	instance := htmlInstance{
+		InstanceID: result.InstanceID,
+		Address:    result.Address,
+		Status:     result.Status,
+		Message:    statusDescriptions[result.Status],
+	}
+
+	for _, s := range statusOrder {
+		if s.status == result.Status {
+			instance.StatusLabel = s.label
+		}
+	}
+	if result.Error != nil {
+		instance.Message = result.Error.Error()
+	}
+
+	for _, key := range sortedKeys(result.Tags) {
+		instance.Tags = append(instance.Tags, key+"="+result.Tags[key])
+	}
+	instance.TagList = strings.Join(instance.Tags, "\n")
+
+	attributes := make([]string, 0, len(result.Drifts))
+	for _, drift := range result.Drifts {
+		attributes = append(attributes, drift.Attribute)
+		instance.Drifts = append(instance.Drifts, newHTMLDrift(drift))
+	}
+	instance.AttributeList = strings.Join(attributes, " ")
+
+	return instance
+}
+
+func newHTMLDrift(drift detector.AttributeDrift) htmlDrift {
+	converted := htmlDrift{
+		Attribute: drift.Attribute,
+		Severity:  drift.Severity,
+		Sensitive: drift.Sensitive,
+		AWS:       compactValue(drift.AWSValue),
+		Terraform: compactValue(drift.TerraformValue),
+		Added:     drift.Added,
+		Removed:   drift.Removed,
+	}
+
+	if entries, ok := diffMaps(drift.AWSValue, drift.TerraformValue); ok {
+		for _, entry := range entries {
+			converted.Entries = append(converted.Entries, htmlMapEntry{
+				Key:       entry.Key,
+				AWS:       htmlEntryValue(entry.AWSValue, entry.InAWS),
+				Terraform: htmlEntryValue(entry.TerraformValue, entry.InTerraform),
+				Changed:   entry.Changed(),
+			})
+		}
+	}
+
+	return converted
+}
+
+func htmlEntryValue(val any, present bool) string {
+	if !present {
+		return "(absent)"
+	}
+	if s, ok := val.(string); ok {
+		return s
+	}
+	return compactValue(val)
+}
+
+func percent(count, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return count * 100 / total
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func TestHTMLReporter_Report(t *testing.T) {
+	results := append(sampleResults(), detector.Result{
+		InstanceID: "i-tagged",
+		Status:     detector.StatusDrifted,
+		HasDrift:   true,
+		Drifts: []detector.AttributeDrift{{
+			Attribute:      "tags",
+			AWSValue:       map[string]any{"Name": "<script>alert(1)</script>", "Env": "prod"},
+			TerraformValue: map[string]any{"Name": "web", "Env": "prod"},
+			Severity:       detector.SeverityLow,
+		}},
+		Tags: map[string]string{"Env": "prod"},
+	})
+
+	var out bytes.Buffer
+	if err := NewHTMLReporter(&out, Metadata{StateFile: "terraform.tfstate"}).Report(results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page := out.String()
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		`data-status="drifted"`,
+		`data-attributes="instance_type vpc_security_group_ids user_data"`,
+		`<option value="Env=prod">Env=prod</option>`,
+		`<tr class="changed"><td><code>Name</code></td>`,
+		`<tr><td><code>Env</code></td><td>prod</td><td>prod</td></tr>`,
+		"<code>terraform.tfstate</code>",
+		"throttled",
+		"filter-status",
+		".diff tr.changed td",
+	}
+	for _, want := range expected {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q", want)
+		}
+	}
+
+	if strings.Contains(page, "<script>alert(1)</script>") {
+		t.Errorf("Expected values to be HTML-escaped")
+	}
+	if strings.Contains(page, `"new"`) {
+		t.Errorf("Expected sensitive value to be redacted")
+	}
+}
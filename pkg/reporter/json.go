@@ -3,45 +3,171 @@ package reporter
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
 )
 
-type JSONReporter struct{}
+// JSONSchemaVersion is the version of the JSON report format. It changes
+// only when fields are renamed or removed; new optional fields keep it.
+const JSONSchemaVersion = "1"
 
-func NewJSONReporter() *JSONReporter {
-	return &JSONReporter{}
+// Metadata describes the run a report was generated for
+type Metadata struct {
+	GeneratedAt time.Time
+	StateFile   string
+	Attributes  []string
+	Duration    time.Duration
 }
 
-func (r *JSONReporter) Report(results []detector.Result) {
-	output := struct {
-		Results []detector.Result `json:"results"`
-		Summary struct {
-			Total      int `json:"total"`
-			WithDrift  int `json:"with_drift"`
-			WithErrors int `json:"with_errors"`
-			InSync     int `json:"in_sync"`
-		} `json:"summary"`
-	}{
-		Results: results,
+type JSONReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// UnsafeShowSensitive writes sensitive drift values, set members and tags
+	// into the report verbatim
+	UnsafeShowSensitive bool
+}
+
+func NewJSONReporter(w io.Writer, meta Metadata) *JSONReporter {
+	return &JSONReporter{w: w, Metadata: meta}
+}
+
+// jsonReport is the top-level JSON document, described by
+// schema/report.schema.json
+type jsonReport struct {
+	SchemaVersion string       `json:"schema_version"`
+	Metadata      jsonMetadata `json:"metadata"`
+	Summary       jsonSummary  `json:"summary"`
+	Results       []jsonResult `json:"results"`
+}
+
+type jsonMetadata struct {
+	GeneratedAt       string   `json:"generated_at"`
+	StateFile         string   `json:"state_file,omitempty"`
+	AttributesChecked []string `json:"attributes_checked"`
+	DurationSeconds   float64  `json:"duration_seconds,omitempty"`
+}
+
+type jsonSummary struct {
+	Total      int `json:"total"`
+	WithDrift  int `json:"with_drift"`
+	Deleted    int `json:"deleted_outside_terraform"`
+	NotInState int `json:"not_in_state"`
+	Unmanaged  int `json:"unmanaged"`
+	WithErrors int `json:"with_errors"`
+	InSync     int `json:"in_sync"`
+}
+
+type jsonResult struct {
+	InstanceID string            `json:"instance_id"`
+	Address    string            `json:"address,omitempty"`
+	Status     detector.Status   `json:"status"`
+	HasDrift   bool              `json:"has_drift"`
+	Drifts     []jsonDrift       `json:"drifts"`
+	Error      string            `json:"error,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	LaunchTime string            `json:"launch_time,omitempty"`
+}
+
+type jsonDrift struct {
+	Attribute      string   `json:"attribute"`
+	Path           string   `json:"path,omitempty"`
+	AWSValue       any      `json:"aws_value"`
+	TerraformValue any      `json:"terraform_value"`
+	Added          []string `json:"added,omitempty"`
+	Removed        []string `json:"removed,omitempty"`
+	Severity       string   `json:"severity,omitempty"`
+	Sensitive      bool     `json:"sensitive,omitempty"`
+}
+
+// Report writes the results as a JSON document
+func (r *JSONReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	jsonBytes, err := json.MarshalIndent(newJSONReport(r.Metadata, results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format JSON report: %w", err)
+	}
+
+	if _, err := r.w.Write(append(jsonBytes, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
+// newJSONReport converts results into the versioned JSON document
+func newJSONReport(meta Metadata, results []detector.Result) jsonReport {
+	generatedAt := meta.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	attributes := meta.Attributes
+	if attributes == nil {
+		attributes = []string{}
+	}
+
+	summary := detector.Summarize(results)
+	report := jsonReport{
+		SchemaVersion: JSONSchemaVersion,
+		Metadata: jsonMetadata{
+			GeneratedAt:       generatedAt.UTC().Format(time.RFC3339),
+			StateFile:         meta.StateFile,
+			AttributesChecked: attributes,
+			DurationSeconds:   meta.Duration.Seconds(),
+		},
+		Summary: jsonSummary{
+			Total:      summary.Total,
+			WithDrift:  summary.Drifted,
+			Deleted:    summary.Deleted,
+			NotInState: summary.NotInState,
+			Unmanaged:  summary.Unmanaged,
+			WithErrors: summary.Errors,
+			InSync:     summary.InSync,
+		},
+		Results: make([]jsonResult, 0, len(results)),
 	}
 
 	for _, result := range results {
-		output.Summary.Total++
-		if result.Error != nil {
-			output.Summary.WithErrors++
-		} else if result.HasDrift {
-			output.Summary.WithDrift++
-		} else {
-			output.Summary.InSync++
-		}
+		report.Results = append(report.Results, newJSONResult(result))
 	}
 
-	jsonBytes, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
-		return
+	return report
+}
+
+func newJSONResult(result detector.Result) jsonResult {
+	converted := jsonResult{
+		InstanceID: result.InstanceID,
+		Address:    result.Address,
+		Status:     result.Status,
+		HasDrift:   result.HasDrift,
+		Drifts:     make([]jsonDrift, 0, len(result.Drifts)),
+		Tags:       result.Tags,
 	}
 
-	fmt.Println(string(jsonBytes))
-}
\ No newline at end of file
+	if result.Error != nil {
+		converted.Error = result.Error.Error()
+	}
+	if !result.LaunchTime.IsZero() {
+		converted.LaunchTime = result.LaunchTime.UTC().Format(time.RFC3339)
+	}
+
+	for _, drift := range result.Drifts {
+		converted.Drifts = append(converted.Drifts, jsonDrift{
+			Attribute:      drift.Attribute,
+			Path:           drift.Path,
+			AWSValue:       drift.AWSValue,
+			TerraformValue: drift.TerraformValue,
+			Added:          drift.Added,
+			Removed:        drift.Removed,
+			Severity:       string(drift.Severity),
+			Sensitive:      drift.Sensitive,
+		})
+	}
+
+	return converted
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func sampleResults() []detector.Result {
+	return []detector.Result{
+		{
+			InstanceID: "i-drifted",
+			Address:    "aws_instance.web",
+			Status:     detector.StatusDrifted,
+			HasDrift:   true,
+			Drifts: []detector.AttributeDrift{
+				{Attribute: "instance_type", Path: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium", Severity: detector.SeverityMedium},
+				{Attribute: "vpc_security_group_ids", AWSValue: []string{"sg-1", "sg-2"}, TerraformValue: []string{"sg-1"}, Added: []string{"sg-2"}, Severity: detector.SeverityCritical},
+				{Attribute: "user_data", AWSValue: "new", TerraformValue: "old", Severity: detector.SeverityLow, Sensitive: true},
+			},
+			Tags: map[string]string{"Name": "web"},
+		},
+		{InstanceID: "i-sync", Address: "aws_instance.api", Status: detector.StatusInSync},
+		{InstanceID: "i-broken", Status: detector.StatusError, Error: errors.New("throttled")},
+		{InstanceID: "i-stray", Status: detector.StatusUnmanaged, LaunchTime: time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)},
+	}
+}
+
+func TestJSONReport_MatchesSchema(t *testing.T) {
+	schema, err := jsonschema.CompileString("report.schema.json", string(ReportSchema))
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+
+	meta := Metadata{
+		GeneratedAt: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		StateFile:   "terraform.tfstate",
+		Attributes:  []string{"instance_type", "vpc_security_group_ids", "user_data"},
+		Duration:    1500 * time.Millisecond,
+	}
+	data, err := json.Marshal(newJSONReport(meta, detector.Redact(sampleResults())))
+	if err != nil {
+		t.Fatalf("Failed to marshal report: %v", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("Report does not match schema: %v", err)
+	}
+
+	out := string(data)
+	for _, want := range []string{`"schema_version":"1"`, `"instance_id":"i-drifted"`, `"error":"throttled"`, `"generated_at":"2024-02-01T12:00:00Z"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %s", want)
+		}
+	}
+	if strings.Contains(out, `"new"`) {
+		t.Errorf("Expected sensitive value to be redacted")
+	}
+}
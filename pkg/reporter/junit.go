@@ -0,0 +1,208 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+// JUnitReporter writes JUnit XML for CI test dashboards. Each instance is a
+// testsuite and each checked attribute a testcase; drifted attributes fail
+// and instances that could not be checked error.
+type JUnitReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// UnsafeShowSensitive puts sensitive drift values and set members into
+	// failure bodies, which CI servers keep with the build
+	UnsafeShowSensitive bool
+}
+
+func NewJUnitReporter(w io.Writer, meta Metadata) *JUnitReporter {
+	return &JUnitReporter{w: w, Metadata: meta}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// Report writes the results as a JUnit XML document
+func (r *JUnitReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	xmlBytes, err := xml.MarshalIndent(r.newTestSuites(results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format JUnit report: %w", err)
+	}
+
+	output := append([]byte(xml.Header), xmlBytes...)
+	if _, err := r.w.Write(append(output, '\n')); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+func (r *JUnitReporter) newTestSuites(results []detector.Result) junitTestSuites {
+	suites := junitTestSuites{
+		Name:   "ec2-drift-detector",
+		Suites: make([]junitTestSuite, 0, len(results)),
+	}
+
+	for _, result := range results {
+		suite := r.newTestSuite(result)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return suites
+}
+
+func (r *JUnitReporter) newTestSuite(result detector.Result) junitTestSuite {
+	className := result.Address
+	if className == "" {
+		className = result.InstanceID
+	}
+
+	suite := junitTestSuite{
+		Name: className,
+		Properties: []junitProperty{
+			{Name: "instance_id", Value: result.InstanceID},
+			{Name: "status", Value: string(result.Status)},
+		},
+	}
+	if !r.Metadata.GeneratedAt.IsZero() {
+		suite.Timestamp = r.Metadata.GeneratedAt.UTC().Format(time.RFC3339)
+	}
+
+	// Errors and failures follow detector.Policy, so the JUnit file agrees
+	// with the exit code of --fail-on error and --fail-on drift
+	switch result.Status {
+	case detector.StatusError, detector.StatusNotInState:
+		message := "instance could not be checked"
+		if result.Error != nil {
+			message = result.Error.Error()
+		} else if description, ok := statusDescriptions[result.Status]; ok {
+			message = description
+		}
+		suite.Cases = []junitTestCase{{
+			Name:      "check",
+			ClassName: className,
+			Error:     &junitProblem{Message: message, Type: string(result.Status), Body: message},
+		}}
+	case detector.StatusDeleted, detector.StatusUnmanaged:
+		message := statusDescriptions[result.Status]
+		suite.Cases = []junitTestCase{{
+			Name:      "managed_by_terraform",
+			ClassName: className,
+			Failure:   &junitProblem{Message: message, Type: string(result.Status), Body: message},
+		}}
+	default:
+		suite.Cases = r.attributeCases(className, result)
+	}
+
+	for _, testCase := range suite.Cases {
+		suite.Tests++
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+		if testCase.Error != nil {
+			suite.Errors++
+		}
+	}
+
+	return suite
+}
+
+// attributeCases creates one testcase per checked attribute. Attributes
+// that drifted but are missing from the metadata are still reported.
+func (r *JUnitReporter) attributeCases(className string, result detector.Result) []junitTestCase {
+	drifts := make(map[string]detector.AttributeDrift, len(result.Drifts))
+	for _, drift := range result.Drifts {
+		drifts[drift.Attribute] = drift
+	}
+
+	attributes := append([]string{}, r.Metadata.Attributes...)
+	checked := make(map[string]bool, len(attributes))
+	for _, attr := range attributes {
+		checked[attr] = true
+	}
+	for _, drift := range result.Drifts {
+		if !checked[drift.Attribute] {
+			checked[drift.Attribute] = true
+			attributes = append(attributes, drift.Attribute)
+		}
+	}
+
+	cases := make([]junitTestCase, 0, len(attributes))
+	for _, attr := range attributes {
+		testCase := junitTestCase{Name: attr, ClassName: className}
+		if drift, ok := drifts[attr]; ok {
+			testCase.Failure = &junitProblem{
+				Message: fmt.Sprintf("%s differs from Terraform", attr),
+				Type:    "drift",
+				Body:    driftDetails(drift),
+			}
+		}
+		cases = append(cases, testCase)
+	}
+	return cases
+}
+
+// driftDetails describes a drift over several plain-text lines
+func driftDetails(drift detector.AttributeDrift) string {
+	lines := []string{
+		"AWS value:       " + compactValue(drift.AWSValue),
+		"Terraform value: " + compactValue(drift.TerraformValue),
+	}
+	if len(drift.Added) > 0 {
+		lines = append(lines, "Added in AWS:    "+strings.Join(drift.Added, ", "))
+	}
+	if len(drift.Removed) > 0 {
+		lines = append(lines, "Removed in AWS:  "+strings.Join(drift.Removed, ", "))
+	}
+	if drift.Severity != "" {
+		lines = append(lines, "Severity:        "+string(drift.Severity))
+	}
+	return strings.Join(lines, "\n")
+}
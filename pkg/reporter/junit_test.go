@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func TestJUnitReporter_Report(t *testing.T) {
+	var out bytes.Buffer
+
+	meta := Metadata{Attributes: []string{"instance_type", "ami", "vpc_security_group_ids", "user_data"}}
+	if err := NewJUnitReporter(&out, meta).Report(sampleResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v", err)
+	}
+
+	if len(suites.Suites) != 4 {
+		t.Fatalf("Expected 4 testsuites, got %d", len(suites.Suites))
+	}
+
+	tests := []struct {
+		name     string
+		tests    int
+		failures int
+		errors   int
+	}{
+		{"aws_instance.web", 4, 3, 0},
+		{"aws_instance.api", 4, 0, 0},
+		{"i-broken", 1, 0, 1},
+		{"i-stray", 1, 1, 0},
+	}
+
+	for i, tt := range tests {
+		suite := suites.Suites[i]
+		if suite.Name != tt.name || suite.Tests != tt.tests || suite.Failures != tt.failures || suite.Errors != tt.errors {
+			t.Errorf("Expected suite %s with %d tests, %d failures, %d errors; got %s with %d, %d, %d",
+				tt.name, tt.tests, tt.failures, tt.errors, suite.Name, suite.Tests, suite.Failures, suite.Errors)
+		}
+	}
+
+	if suites.Tests != 10 || suites.Failures != 4 || suites.Errors != 1 {
+		t.Errorf("Unexpected totals: %d tests, %d failures, %d errors", suites.Tests, suites.Failures, suites.Errors)
+	}
+
+	broken := suites.Suites[2].Cases[0]
+	if broken.Error == nil || broken.Error.Message != "throttled" {
+		t.Errorf("Expected error message throttled, got %+v", broken.Error)
+	}
+}
+
+func TestJUnitReporter_NotInStateIsError(t *testing.T) {
+	var out bytes.Buffer
+
+	results := []detector.Result{{InstanceID: "i-gone", Status: detector.StatusNotInState}}
+	if err := NewJUnitReporter(&out, Metadata{}).Report(results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v", err)
+	}
+	if suites.Errors != 1 || suites.Failures != 0 {
+		t.Errorf("Expected 1 error and no failures, got %d errors and %d failures", suites.Errors, suites.Failures)
+	}
+}
@@ -0,0 +1,213 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+// GitHubCommentLimit is the maximum size of a GitHub pull request comment
+const GitHubCommentLimit = 65536
+
+// MarkdownReporter writes a report suitable for pull/merge request comments:
+// a summary table followed by a collapsible section per instance that needs
+// attention
+type MarkdownReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// MaxBytes caps the report size; sections that do not fit are replaced
+	// by a note saying how many were omitted. Zero means no limit.
+	MaxBytes int
+
+	// UnsafeShowSensitive shows sensitive drift values in the comment, to
+	// everyone who can read the pull request
+	UnsafeShowSensitive bool
+}
+
+func NewMarkdownReporter(w io.Writer, meta Metadata) *MarkdownReporter {
+	return &MarkdownReporter{w: w, Metadata: meta}
+}
+
+// Report writes the results as Markdown
+func (r *MarkdownReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	if _, err := r.w.Write(r.render(results)); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+func (r *MarkdownReporter) render(results []detector.Result) []byte {
+	var b bytes.Buffer
+	summary := detector.Summarize(results)
+
+	b.WriteString("## EC2 Drift Report\n\n")
+	if r.Metadata.StateFile != "" {
+		fmt.Fprintf(&b, "State file: `%s`\n\n", r.Metadata.StateFile)
+	}
+
+	b.WriteString("| Status | Instances |\n|---|---:|\n")
+	rows := []struct {
+		label string
+		count int
+	}{
+		{"Drifted", summary.Drifted},
+		{"Deleted outside Terraform", summary.Deleted},
+		{"Not in Terraform state", summary.NotInState},
+		{"Unmanaged", summary.Unmanaged},
+		{"Errors", summary.Errors},
+		{"In sync", summary.InSync},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %d |\n", row.label, row.count)
+	}
+	fmt.Fprintf(&b, "| **Total** | **%d** |\n", summary.Total)
+
+	sections := make([]string, 0)
+	for _, result := range results {
+		if result.Status != detector.StatusInSync {
+			sections = append(sections, markdownSection(result))
+		}
+	}
+	if len(sections) == 0 {
+		b.WriteString("\nAll checked instances match Terraform.\n")
+		return r.capSize(b.Bytes())
+	}
+
+	// The header and summary table in b count against MaxBytes. Room is
+	// reserved for the omission note while more sections follow.
+	reserve := len(omittedNote(len(sections)))
+	for i, section := range sections {
+		remaining := len(sections) - i - 1
+		limit := r.MaxBytes
+		if remaining > 0 {
+			limit -= reserve
+		}
+
+		if r.MaxBytes > 0 && b.Len()+len(section) > limit {
+			b.WriteString(omittedNote(len(sections) - i))
+			break
+		}
+		b.WriteString(section)
+	}
+
+	return r.capSize(b.Bytes())
+}
+
+// capSize enforces MaxBytes when even the header and omission note do not
+// fit, cutting at a rune boundary
+func (r *MarkdownReporter) capSize(report []byte) []byte {
+	if r.MaxBytes <= 0 || len(report) <= r.MaxBytes {
+		return report
+	}
+	return bytes.ToValidUTF8(report[:r.MaxBytes], nil)
+}
+
+// markdownSection renders a collapsible section for one instance
+func markdownSection(result detector.Result) string {
+	var b strings.Builder
+
+	name := fmt.Sprintf("<code>%s</code>", escapeHTML(result.InstanceID))
+	if result.Address != "" {
+		name = fmt.Sprintf("<code>%s</code> (%s)", escapeHTML(result.Address), name)
+	}
+
+	var headline string
+	switch result.Status {
+	case detector.StatusDrifted:
+		headline = fmt.Sprintf("%d drifted attribute(s)", len(result.Drifts))
+	case detector.StatusError:
+		headline = "error"
+	default:
+		headline = strings.ReplaceAll(string(result.Status), "_", " ")
+	}
+
+	fmt.Fprintf(&b, "\n<details>\n<summary>%s: %s</summary>\n\n", name, headline)
+
+	switch result.Status {
+	case detector.StatusDrifted:
+		b.WriteString("| Attribute | Severity | AWS | Terraform |\n|---|---|---|---|\n")
+		for _, drift := range result.Drifts {
+			writeDriftRows(&b, drift)
+		}
+	case detector.StatusError:
+		message := "instance could not be checked"
+		if result.Error != nil {
+			message = result.Error.Error()
+		}
+		fmt.Fprintf(&b, "%s\n", escapeMarkdownCell(message))
+	default:
+		fmt.Fprintf(&b, "%s\n", statusDescriptions[result.Status])
+	}
+
+	b.WriteString("\n</details>\n")
+	return b.String()
+}
+
+// writeDriftRows writes one table row per drift, expanding map values such
+// as tags into a row per changed key
+func writeDriftRows(b *strings.Builder, drift detector.AttributeDrift) {
+	attr := drift.Attribute
+	if drift.Sensitive {
+		attr += " (sensitive)"
+	}
+
+	if entries, ok := diffMaps(drift.AWSValue, drift.TerraformValue); ok {
+		for _, entry := range entries {
+			if !entry.Changed() {
+				continue
+			}
+			fmt.Fprintf(b, "| `%s.%s` | %s | %s | %s |\n",
+				escapeMarkdownCell(drift.Attribute), escapeMarkdownCell(entry.Key), drift.Severity,
+				markdownEntryValue(entry.AWSValue, entry.InAWS), markdownEntryValue(entry.TerraformValue, entry.InTerraform))
+		}
+		return
+	}
+
+	awsValue := markdownValue(drift.AWSValue)
+	tfValue := markdownValue(drift.TerraformValue)
+	if len(drift.Added) > 0 {
+		awsValue += "<br>added: " + markdownValue(drift.Added)
+	}
+	if len(drift.Removed) > 0 {
+		tfValue += "<br>removed: " + markdownValue(drift.Removed)
+	}
+	fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", escapeMarkdownCell(attr), drift.Severity, awsValue, tfValue)
+}
+
+func markdownEntryValue(val any, present bool) string {
+	if !present {
+		return "_absent_"
+	}
+	return markdownValue(val)
+}
+
+// markdownValue formats a value as inline code that is safe in a table cell
+func markdownValue(val any) string {
+	text := escapeMarkdownCell(compactValue(val))
+	if strings.Contains(text, "`") {
+		return text
+	}
+	return "`" + text + "`"
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func omittedNote(count int) string {
+	return fmt.Sprintf("\n_%d more instance(s) omitted to stay under the size limit._\n", count)
+}
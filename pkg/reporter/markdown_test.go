@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+func TestMarkdownReporter_Report(t *testing.T) {
+	results := append(sampleResults(), detector.Result{
+		InstanceID: "i-tagged",
+		Status:     detector.StatusDrifted,
+		HasDrift:   true,
+		Drifts: []detector.AttributeDrift{{
+			Attribute:      "tags",
+			AWSValue:       map[string]any{"Name": "web", "Env": "prod|blue"},
+			TerraformValue: map[string]any{"Name": "web", "Owner": "team"},
+			Severity:       detector.SeverityLow,
+		}},
+	})
+
+	var out bytes.Buffer
+	if err := NewMarkdownReporter(&out, Metadata{}).Report(results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report := out.String()
+
+	expected := []string{
+		"| Drifted | 2 |",
+		"| **Total** | **5** |",
+		"<summary><code>aws_instance.web</code> (<code>i-drifted</code>): 3 drifted attribute(s)</summary>",
+		"| `instance_type` | medium | `\"t3.large\"` | `\"t3.medium\"` |",
+		"| `tags.Env` | low | `\"prod\\|blue\"` | _absent_ |",
+		"| `tags.Owner` | low | _absent_ | `\"team\"` |",
+		"throttled",
+	}
+	for _, want := range expected {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	if !strings.Contains(report, "<br>added: `[\"sg-2\"]`") || strings.Contains(report, "removed: `null`") {
+		t.Errorf("Expected only non-empty set changes to be listed")
+	}
+
+	if strings.Contains(report, "tags.Name") {
+		t.Errorf("Expected unchanged tags to be left out")
+	}
+	if strings.Contains(report, "i-sync") {
+		t.Errorf("Expected in-sync instances to have no section")
+	}
+	if strings.Count(report, "<details>") != strings.Count(report, "</details>") {
+		t.Errorf("Unbalanced details sections")
+	}
+}
+
+func TestMarkdownReporter_Truncates(t *testing.T) {
+	results := make([]detector.Result, 0, 50)
+	for i := 0; i < 50; i++ {
+		results = append(results, detector.Result{
+			InstanceID: fmt.Sprintf("i-%03d", i),
+			Status:     detector.StatusDrifted,
+			HasDrift:   true,
+			Drifts: []detector.AttributeDrift{
+				{Attribute: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium"},
+			},
+		})
+	}
+
+	var out bytes.Buffer
+	rep := NewMarkdownReporter(&out, Metadata{})
+	rep.MaxBytes = 2000
+	if err := rep.Report(results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := out.String()
+	if len(report) > rep.MaxBytes {
+		t.Errorf("Expected at most %d bytes, got %d", rep.MaxBytes, len(report))
+	}
+	if !strings.Contains(report, "more instance(s) omitted") {
+		t.Errorf("Expected omission note, got:\n%s", report)
+	}
+	if strings.Count(report, "<details>") != strings.Count(report, "</details>") {
+		t.Errorf("Truncation split a details section")
+	}
+}
+
+func TestMarkdownReporter_TruncatesAfterLongHeader(t *testing.T) {
+	results := []detector.Result{
+		{InstanceID: "i-001", Status: detector.StatusDeleted},
+		{InstanceID: "i-002", Status: detector.StatusDeleted},
+	}
+	meta := Metadata{StateFile: strings.Repeat("é", 200) + ".tfstate"}
+
+	var full bytes.Buffer
+	if err := NewMarkdownReporter(&full, meta).Report(results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	header := full.String()[:strings.Index(full.String(), "\n<details>")]
+
+	for _, maxBytes := range []int{len(header) + 10, len(header) - 1} {
+		var out bytes.Buffer
+		rep := NewMarkdownReporter(&out, meta)
+		rep.MaxBytes = maxBytes
+		if err := rep.Report(results); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if out.Len() > maxBytes {
+			t.Errorf("Expected at most %d bytes, got %d", maxBytes, out.Len())
+		}
+		if !utf8.Valid(out.Bytes()) {
+			t.Errorf("Expected valid UTF-8 at a limit of %d bytes", maxBytes)
+		}
+		if strings.Contains(out.String(), "<details>") {
+			t.Errorf("Expected no sections at a limit of %d bytes", maxBytes)
+		}
+	}
+}
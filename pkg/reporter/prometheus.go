@@ -0,0 +1,209 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+const (
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+	defaultPushJob        = "ec2_drift_detector"
+	defaultPushTimeout    = 10 * time.Second
+)
+
+// PrometheusReporter writes metrics in the Prometheus text exposition
+// format. Besides the writer, it can atomically replace a node_exporter
+// textfile collector file and push to a Pushgateway.
+type PrometheusReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// TextfilePath is a *.prom file in the textfile collector directory
+	TextfilePath string
+
+	// PushgatewayURL is the base URL of a Pushgateway-compatible endpoint;
+	// metrics replace the group at /metrics/job/<PushJob>
+	PushgatewayURL string
+	PushJob        string
+	Client         *http.Client
+
+	// UnsafeShowSensitive keeps a sensitive Name tag in the name label.
+	// Otherwise the label is left empty, since hashes change every run.
+	UnsafeShowSensitive bool
+}
+
+// NewPrometheusReporter creates a reporter writing metrics to w, which may
+// be nil when only the textfile or Pushgateway outputs are wanted
+func NewPrometheusReporter(w io.Writer, meta Metadata) *PrometheusReporter {
+	return &PrometheusReporter{
+		w:        w,
+		Metadata: meta,
+		PushJob:  defaultPushJob,
+		Client:   &http.Client{Timeout: defaultPushTimeout},
+	}
+}
+
+// Report renders the metrics and sends them to every configured output
+func (r *PrometheusReporter) Report(results []detector.Result) error {
+	return r.ReportContext(context.Background(), results)
+}
+
+// ReportContext is Report with ctx bounding the Pushgateway request
+func (r *PrometheusReporter) ReportContext(ctx context.Context, results []detector.Result) error {
+	metrics := r.render(results)
+
+	if r.w != nil {
+		if _, err := r.w.Write(metrics); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+
+	if r.TextfilePath != "" {
+		if err := writeFileAtomic(r.TextfilePath, metrics); err != nil {
+			return fmt.Errorf("failed to write metrics textfile: %w", err)
+		}
+	}
+
+	if r.PushgatewayURL != "" {
+		if err := r.push(ctx, metrics); err != nil {
+			return fmt.Errorf("failed to push metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PrometheusReporter) render(results []detector.Result) []byte {
+	var b bytes.Buffer
+	summary := detector.Summarize(results)
+
+	writeMetric(&b, "ec2_drift_instances_checked", "Instances checked in the last run.")
+	fmt.Fprintf(&b, "ec2_drift_instances_checked %d\n", summary.Total)
+
+	writeMetric(&b, "ec2_drift_instances_drifted", "Instances with at least one drifted attribute.")
+	fmt.Fprintf(&b, "ec2_drift_instances_drifted %d\n", summary.Drifted)
+
+	writeMetric(&b, "ec2_drift_instances_errored", "Instances that could not be checked.")
+	fmt.Fprintf(&b, "ec2_drift_instances_errored %d\n", summary.Errors)
+
+	writeMetric(&b, "ec2_drift_instances", "Instances checked in the last run by status.")
+	statusCounts := map[detector.Status]int{
+		detector.StatusInSync:     summary.InSync,
+		detector.StatusDrifted:    summary.Drifted,
+		detector.StatusDeleted:    summary.Deleted,
+		detector.StatusNotInState: summary.NotInState,
+		detector.StatusUnmanaged:  summary.Unmanaged,
+		detector.StatusError:      summary.Errors,
+	}
+	for _, s := range statusOrder {
+		fmt.Fprintf(&b, "ec2_drift_instances{status=%s} %d\n", labelValue(string(s.status)), statusCounts[s.status])
+	}
+
+	writeMetric(&b, "ec2_drift_attribute_drifts", "Instances on which an attribute drifted.")
+	attributeCounts := make(map[string]int)
+	for _, result := range results {
+		for _, drift := range result.Drifts {
+			attributeCounts[drift.Attribute]++
+		}
+	}
+	for _, attr := range sortedKeys(attributeCounts) {
+		fmt.Fprintf(&b, "ec2_drift_attribute_drifts{attribute=%s} %d\n", labelValue(attr), attributeCounts[attr])
+	}
+
+	writeMetric(&b, "ec2_drift_instance_drifted_attributes", "Drifted attributes per instance.")
+	for _, result := range results {
+		name := result.Tags["Name"]
+		if !r.UnsafeShowSensitive && result.IsSensitive("tags.Name") {
+			name = ""
+		}
+		fmt.Fprintf(&b, "ec2_drift_instance_drifted_attributes{instance_id=%s,name=%s,address=%s,status=%s} %d\n",
+			labelValue(result.InstanceID), labelValue(name), labelValue(result.Address),
+			labelValue(string(result.Status)), len(result.Drifts))
+	}
+
+	writeMetric(&b, "ec2_drift_run_duration_seconds", "Duration of the last run.")
+	fmt.Fprintf(&b, "ec2_drift_run_duration_seconds %g\n", r.Metadata.Duration.Seconds())
+
+	generatedAt := r.Metadata.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	writeMetric(&b, "ec2_drift_last_run_timestamp_seconds", "Unix time the last run finished.")
+	fmt.Fprintf(&b, "ec2_drift_last_run_timestamp_seconds %d\n", generatedAt.Unix())
+
+	return b.Bytes()
+}
+
+// push replaces the job's metric group on the Pushgateway
+func (r *PrometheusReporter) push(ctx context.Context, metrics []byte) error {
+	job := r.PushJob
+	if job == "" {
+		job = defaultPushJob
+	}
+	endpoint := strings.TrimRight(r.PushgatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(metrics))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", prometheusContentType)
+
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultPushTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func writeMetric(b *bytes.Buffer, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// labelValue quotes a label value, escaping backslashes, quotes and newlines
+func labelValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// writeFileAtomic writes data to a temporary file in the target directory
+// and renames it into place, so the collector never reads a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
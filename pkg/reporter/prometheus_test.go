@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusReporter_Report(t *testing.T) {
+	var pushed, method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pushed, method, path = string(body), r.Method, r.URL.Path
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	textfile := filepath.Join(t.TempDir(), "drift.prom")
+
+	rep := NewPrometheusReporter(&out, Metadata{
+		GeneratedAt: time.Unix(1700000000, 0),
+		Duration:    2500 * time.Millisecond,
+	})
+	rep.TextfilePath = textfile
+	rep.PushgatewayURL = server.URL + "/"
+	rep.PushJob = "drift"
+
+	if err := rep.Report(sampleResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	metrics := out.String()
+	expected := []string{
+		"# TYPE ec2_drift_instances_checked gauge",
+		"ec2_drift_instances_checked 4\n",
+		"ec2_drift_instances_drifted 1\n",
+		"ec2_drift_instances_errored 1\n",
+		`ec2_drift_instances{status="unmanaged"} 1`,
+		`ec2_drift_attribute_drifts{attribute="instance_type"} 1`,
+		`ec2_drift_instance_drifted_attributes{instance_id="i-drifted",name="web",address="aws_instance.web",status="drifted"} 3`,
+		"ec2_drift_run_duration_seconds 2.5\n",
+		"ec2_drift_last_run_timestamp_seconds 1700000000\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, metrics)
+		}
+	}
+
+	written, err := os.ReadFile(textfile)
+	if err != nil || string(written) != metrics {
+		t.Errorf("Expected textfile to match metrics (err: %v)", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(textfile))
+	if len(entries) != 1 {
+		t.Errorf("Expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/drift" || pushed != metrics {
+		t.Errorf("Unexpected push: %s %s", method, path)
+	}
+}
+
+func TestPrometheusReporter_PushFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad metrics", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	rep := NewPrometheusReporter(nil, Metadata{})
+	rep.PushgatewayURL = server.URL
+
+	err := rep.Report(sampleResults())
+	if err == nil || !strings.Contains(err.Error(), "bad metrics") {
+		t.Errorf("Expected push error, got %v", err)
+	}
+}
+
+func TestPrometheusReporter_PushCancelled(t *testing.T) {
+	var pushed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed = true
+	}))
+	defer server.Close()
+
+	rep := NewPrometheusReporter(nil, Metadata{})
+	rep.PushgatewayURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewMultiReporter(rep).ReportContext(ctx, sampleResults())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if pushed {
+		t.Errorf("Expected no push after cancellation")
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	if got := labelValue("a\"b\\c\nd"); got != `"a\"b\\c\nd"` {
+		t.Errorf("Unexpected escaping: %s", got)
+	}
+}
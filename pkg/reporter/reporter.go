@@ -1,8 +1,75 @@
 package reporter
 
-import "github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+import (
+	"context"
+	"errors"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
 
 // Reporter interface for reporting drift results
 type Reporter interface {
-	Report(results []detector.Result)
-}
\ No newline at end of file
+	Report(results []detector.Result) error
+}
+
+// ContextReporter is implemented by reporters that make network calls,
+// so they can be cancelled
+type ContextReporter interface {
+	ReportContext(ctx context.Context, results []detector.Result) error
+}
+
+// ReportContext reports through ReportContext when r supports it and
+// through Report otherwise
+func ReportContext(ctx context.Context, r Reporter, results []detector.Result) error {
+	if cr, ok := r.(ContextReporter); ok {
+		return cr.ReportContext(ctx, results)
+	}
+	return r.Report(results)
+}
+
+// MultiReporter fans results out to several reporters, e.g. console output
+// to the terminal and JSON to a file in the same run
+type MultiReporter struct {
+	reporters []Reporter
+}
+
+func NewMultiReporter(reporters ...Reporter) *MultiReporter {
+	return &MultiReporter{reporters: reporters}
+}
+
+// Report runs every reporter, even after one fails, and returns their
+// joined errors
+func (m *MultiReporter) Report(results []detector.Result) error {
+	return m.ReportContext(context.Background(), results)
+}
+
+// ReportContext is Report with ctx passed to every ContextReporter
+func (m *MultiReporter) ReportContext(ctx context.Context, results []detector.Result) error {
+	var errs []error
+	for _, r := range m.reporters {
+		if err := ReportContext(ctx, r, results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// statusOrder lists every status with its label, in report order
+var statusOrder = []struct {
+	status detector.Status
+	label  string
+}{
+	{detector.StatusDrifted, "Drifted"},
+	{detector.StatusDeleted, "Deleted outside Terraform"},
+	{detector.StatusNotInState, "Not in state"},
+	{detector.StatusUnmanaged, "Unmanaged"},
+	{detector.StatusError, "Error"},
+	{detector.StatusInSync, "In sync"},
+}
+
+// statusDescriptions explains statuses that have no attribute comparison
+var statusDescriptions = map[detector.Status]string{
+	detector.StatusDeleted:    "Instance is in Terraform state but no longer exists in AWS",
+	detector.StatusNotInState: "Instance was not found in Terraform state or in AWS",
+	detector.StatusUnmanaged:  "Instance exists in AWS but is not managed by Terraform",
+}
@@ -0,0 +1,155 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestMultiReporter_WritesEveryFormat(t *testing.T) {
+	var console, jsonOut bytes.Buffer
+
+	multi := NewMultiReporter(
+		NewConsoleReporter(&console),
+		NewJSONReporter(&jsonOut, Metadata{}),
+	)
+	if err := multi.Report(sampleResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(console.String(), "Instance: i-drifted") {
+		t.Errorf("Expected console output for i-drifted, got:\n%s", console.String())
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(jsonOut.Bytes(), &doc); err != nil {
+		t.Errorf("Expected valid JSON output, got %v", err)
+	}
+}
+
+func TestMultiReporter_JoinsErrors(t *testing.T) {
+	var jsonOut bytes.Buffer
+
+	multi := NewMultiReporter(
+		NewConsoleReporter(failingWriter{}),
+		NewJSONReporter(&jsonOut, Metadata{}),
+	)
+	err := multi.Report(sampleResults())
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Expected write error, got %v", err)
+	}
+
+	if jsonOut.Len() == 0 {
+		t.Errorf("Expected later reporters to run after a failure")
+	}
+}
+
+// sensitiveTagResults carry secrets in tags on every kind of result
+func sensitiveTagResults() []detector.Result {
+	return []detector.Result{
+		{
+			InstanceID:     "i-drifted",
+			Address:        "aws_instance.web",
+			Status:         detector.StatusDrifted,
+			HasDrift:       true,
+			Drifts:         []detector.AttributeDrift{{Attribute: "instance_type", AWSValue: "t3.large", TerraformValue: "t3.medium"}},
+			Tags:           map[string]string{"Name": "web", "Secret": "hunter2"},
+			SensitivePaths: []string{"tags.Secret"},
+		},
+		{
+			InstanceID:     "i-sync",
+			Address:        "aws_instance.api",
+			Status:         detector.StatusInSync,
+			Tags:           map[string]string{"Name": "api", "Secret": "hunter2"},
+			SensitivePaths: []string{"tags.Secret"},
+		},
+		{
+			InstanceID:     "i-stray",
+			Status:         detector.StatusUnmanaged,
+			Tags:           map[string]string{"Name": "classified-name"},
+			SensitivePaths: []string{"tags"},
+		},
+	}
+}
+
+func TestReporters_RedactSensitiveTags(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(w io.Writer, unsafe bool) Reporter
+	}{
+		{"console", func(w io.Writer, unsafe bool) Reporter {
+			r := NewConsoleReporter(w)
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"json", func(w io.Writer, unsafe bool) Reporter {
+			r := NewJSONReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"sarif", func(w io.Writer, unsafe bool) Reporter {
+			r := NewSARIFReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"junit", func(w io.Writer, unsafe bool) Reporter {
+			r := NewJUnitReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"markdown", func(w io.Writer, unsafe bool) Reporter {
+			r := NewMarkdownReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"html", func(w io.Writer, unsafe bool) Reporter {
+			r := NewHTMLReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+		{"prometheus", func(w io.Writer, unsafe bool) Reporter {
+			r := NewPrometheusReporter(w, Metadata{})
+			r.UnsafeShowSensitive = unsafe
+			return r
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.new(&out, false).Report(sensitiveTagResults()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, secret := range []string{"hunter2", "classified-name"} {
+				if strings.Contains(out.String(), secret) {
+					t.Errorf("Expected %q to be redacted, got:\n%s", secret, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReporters_UnsafeShowSensitiveTags(t *testing.T) {
+	var out bytes.Buffer
+	r := NewJSONReporter(&out, Metadata{})
+	r.UnsafeShowSensitive = true
+
+	if err := r.Report(sensitiveTagResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "hunter2") {
+		t.Errorf("Expected sensitive tags verbatim, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,313 @@
+package reporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/detector"
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "ec2-drift-detector"
+	toolURI      = "https://github.com/sanjaesan/ec2-drift-detector"
+
+	// sarifFallbackURI locates results when neither a .tf declaration nor a
+	// repository-relative state file is known. Code scanning rejects
+	// results without a physical location.
+	sarifFallbackURI = "terraform.tfstate"
+)
+
+// SARIFReporter writes drift as SARIF 2.1.0 for GitHub code scanning. Each
+// AttributeDrift becomes one result under a rule per attribute.
+type SARIFReporter struct {
+	w        io.Writer
+	Metadata Metadata
+
+	// Registry supplies rule descriptions; defaults to the built-in attributes
+	Registry *detector.Registry
+
+	// Sources locates resources in .tf files. Results fall back to the state
+	// file when a resource cannot be located, or to terraform.tfstate when
+	// the state is remote or outside the repository.
+	Sources terraform.SourceIndex
+
+	// UnsafeShowSensitive puts sensitive drift values into result messages,
+	// which code scanning keeps with the alert
+	UnsafeShowSensitive bool
+}
+
+func NewSARIFReporter(w io.Writer, meta Metadata) *SARIFReporter {
+	return &SARIFReporter{w: w, Metadata: meta, Registry: detector.NewDefaultRegistry()}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      *sarifMessage      `json:"fullDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// Report writes the drifts as a SARIF log
+func (r *SARIFReporter) Report(results []detector.Result) error {
+	if !r.UnsafeShowSensitive {
+		results = detector.Redact(results)
+	}
+
+	jsonBytes, err := json.MarshalIndent(r.newLog(results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format SARIF report: %w", err)
+	}
+
+	if _, err := r.w.Write(append(jsonBytes, '\n')); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
+
+func (r *SARIFReporter) newLog(results []detector.Result) sarifLog {
+	registry := r.Registry
+	if registry == nil {
+		registry = detector.NewDefaultRegistry()
+	}
+
+	// Rules are listed in attribute order so ruleIndex is stable across runs
+	severities := make(map[string]detector.Severity)
+	for _, result := range results {
+		for _, drift := range result.Drifts {
+			severities[drift.Attribute] = drift.Severity
+		}
+	}
+	attributes := make([]string, 0, len(severities))
+	for attr := range severities {
+		attributes = append(attributes, attr)
+	}
+	sort.Strings(attributes)
+
+	rules := make([]sarifRule, len(attributes))
+	ruleIndex := make(map[string]int, len(attributes))
+	for i, attr := range attributes {
+		rules[i] = newSARIFRule(registry, attr, severities[attr])
+		ruleIndex[attr] = i
+	}
+
+	sarifResults := make([]sarifResult, 0)
+	for _, result := range results {
+		for _, drift := range result.Drifts {
+			sarifResults = append(sarifResults, sarifResult{
+				RuleID:    sarifRuleID(drift.Attribute),
+				RuleIndex: ruleIndex[drift.Attribute],
+				Level:     sarifLevel(drift.Severity),
+				Message:   sarifMessage{Text: driftMessage(result, drift)},
+				Locations: []sarifLocation{r.location(result)},
+				PartialFingerprints: map[string]string{
+					"driftKey/v1": fingerprint(result.InstanceID, drift.Attribute),
+				},
+			})
+		}
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: sarifResults,
+		}},
+	}
+}
+
+func newSARIFRule(registry *detector.Registry, attr string, severity detector.Severity) sarifRule {
+	rule := sarifRule{
+		ID:                   sarifRuleID(attr),
+		Name:                 "Drift in " + attr,
+		ShortDescription:     sarifMessage{Text: fmt.Sprintf("EC2 attribute %s differs from Terraform", attr)},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(severity)},
+		Properties: sarifProperties{
+			SecuritySeverity: securitySeverity(severity),
+			Tags:             []string{"drift", "terraform", "ec2"},
+		},
+	}
+
+	if schema, ok := registry.Lookup(attr); ok && schema.Description != "" {
+		rule.FullDescription = &sarifMessage{Text: schema.Description}
+	}
+	return rule
+}
+
+// location points at the resource's .tf declaration when known, otherwise
+// at the state file, and always carries the Terraform address
+func (r *SARIFReporter) location(result detector.Result) sarifLocation {
+	var location sarifLocation
+
+	if source, ok := r.Sources.Lookup(result.Address); ok {
+		location.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(source.File)},
+			Region:           &sarifRegion{StartLine: source.Line},
+		}
+	} else {
+		location.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: stateURI(r.Metadata.StateFile)},
+		}
+	}
+
+	name := result.Address
+	if name == "" {
+		name = result.InstanceID
+	}
+	location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: name, Kind: "resource"}}
+
+	return location
+}
+
+// stateURI returns the state file as a repository-relative URI, or
+// sarifFallbackURI for remote (e.g. s3://) and absolute paths
+func stateURI(stateFile string) string {
+	if stateFile == "" || strings.Contains(stateFile, "://") || filepath.IsAbs(stateFile) {
+		return sarifFallbackURI
+	}
+	return filepath.ToSlash(filepath.Clean(stateFile))
+}
+
+func sarifRuleID(attr string) string {
+	return "drift/" + attr
+}
+
+// sarifLevel maps drift severity to a SARIF result level
+func sarifLevel(severity detector.Severity) string {
+	switch severity {
+	case detector.SeverityCritical, detector.SeverityHigh:
+		return "error"
+	case detector.SeverityLow:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// securitySeverity maps drift severity to the CVSS-style score GitHub code
+// scanning uses to rank alerts
+func securitySeverity(severity detector.Severity) string {
+	switch severity {
+	case detector.SeverityCritical:
+		return "9.0"
+	case detector.SeverityHigh:
+		return "7.0"
+	case detector.SeverityLow:
+		return "2.0"
+	default:
+		return "5.0"
+	}
+}
+
+func driftMessage(result detector.Result, drift detector.AttributeDrift) string {
+	subject := result.InstanceID
+	if result.Address != "" {
+		subject = fmt.Sprintf("%s (%s)", result.Address, result.InstanceID)
+	}
+	return fmt.Sprintf("%s: %s is %s in AWS but %s in Terraform",
+		subject, drift.Attribute, compactValue(drift.AWSValue), compactValue(drift.TerraformValue))
+}
+
+// compactValue formats a value on a single line
+func compactValue(val any) string {
+	if val == nil {
+		return "<nil>"
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
+
+// fingerprint identifies a drift across runs so code scanning can track it
+func fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,104 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sanjaesan/ec2-drift-detector/pkg/terraform"
+)
+
+func TestSARIFReporter_Report(t *testing.T) {
+	var out bytes.Buffer
+
+	rep := NewSARIFReporter(&out, Metadata{StateFile: "terraform.tfstate"})
+	rep.Sources = terraform.SourceIndex{
+		"aws_instance.web": {File: "infra/main.tf", Line: 12},
+	}
+	if err := rep.Report(sampleResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Expected valid SARIF, got %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: version %s, %d runs", log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if len(run.Results) != 3 || len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("Expected 3 results and 3 rules, got %d and %d", len(run.Results), len(run.Tool.Driver.Rules))
+	}
+
+	levels := make(map[string]string)
+	for _, result := range run.Results {
+		levels[result.RuleID] = result.Level
+
+		rule := run.Tool.Driver.Rules[result.RuleIndex]
+		if rule.ID != result.RuleID {
+			t.Errorf("ruleIndex of %s points at %s", result.RuleID, rule.ID)
+		}
+
+		physical := result.Locations[0].PhysicalLocation
+		if physical == nil || physical.ArtifactLocation.URI != "infra/main.tf" || physical.Region.StartLine != 12 {
+			t.Errorf("Expected location infra/main.tf:12, got %+v", physical)
+		}
+		if result.Locations[0].LogicalLocations[0].FullyQualifiedName != "aws_instance.web" {
+			t.Errorf("Expected logical location aws_instance.web, got %+v", result.Locations[0].LogicalLocations)
+		}
+	}
+
+	expected := map[string]string{
+		"drift/instance_type":          "warning",
+		"drift/vpc_security_group_ids": "error",
+		"drift/user_data":              "note",
+	}
+	for rule, level := range expected {
+		if levels[rule] != level {
+			t.Errorf("Expected %s level %s, got %s", rule, level, levels[rule])
+		}
+	}
+
+	if strings.Contains(out.String(), `\"new\"`) {
+		t.Errorf("Expected sensitive value to be redacted")
+	}
+}
+
+func TestSARIFReporter_StateFileFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		stateFile string
+		uri       string
+	}{
+		{"relative state file", "./envs/prod/terraform.tfstate", "envs/prod/terraform.tfstate"},
+		{"no state file", "", "terraform.tfstate"},
+		{"S3 state", "s3://tf-state/prod/terraform.tfstate", "terraform.tfstate"},
+		{"absolute path", "/var/lib/terraform/prod.tfstate", "terraform.tfstate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			if err := NewSARIFReporter(&out, Metadata{StateFile: tt.stateFile}).Report(sampleResults()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var log sarifLog
+			if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+				t.Fatalf("Expected valid SARIF, got %v", err)
+			}
+
+			for _, result := range log.Runs[0].Results {
+				physical := result.Locations[0].PhysicalLocation
+				if physical == nil || physical.ArtifactLocation.URI != tt.uri {
+					t.Errorf("Expected location %s, got %+v", tt.uri, physical)
+				}
+			}
+		})
+	}
+}
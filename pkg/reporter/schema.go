@@ -0,0 +1,8 @@
+package reporter
+
+import _ "embed"
+
+// ReportSchema is the JSON Schema document for the output of JSONReporter
+//
+//go:embed schema/report.schema.json
+var ReportSchema []byte
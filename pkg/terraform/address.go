@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Address returns the full Terraform address of an instance of the resource,
+// e.g. module.web.aws_instance.app["blue"]
+func (r Resource) Address(instance ResourceInstance) string {
+	var b strings.Builder
+
+	if r.Module != "" {
+		b.WriteString(r.Module)
+		b.WriteString(".")
+	}
+
+	if r.Mode == "data" {
+		b.WriteString("data.")
+	}
+
+	b.WriteString(r.Type)
+	b.WriteString(".")
+	b.WriteString(r.Name)
+	b.WriteString(formatIndexKey(instance.IndexKey))
+
+	return b.String()
+}
+
+// formatIndexKey renders a count or for_each key the way Terraform does
+func formatIndexKey(key any) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%s]", strconv.Quote(k))
+	case float64:
+		return fmt.Sprintf("[%d]", int64(k))
+	case int:
+		return fmt.Sprintf("[%d]", k)
+	case int64:
+		return fmt.Sprintf("[%d]", k)
+	default:
+		return fmt.Sprintf("[%v]", k)
+	}
+}
@@ -0,0 +1,11 @@
+package terraform
+
+import "errors"
+
+// ErrInstanceNotInState is returned when an instance ID is not present in
+// the Terraform state
+var ErrInstanceNotInState = errors.New("instance not found in Terraform state")
+
+// ErrUnsupportedStateVersion is returned for state files in a format the
+// parser does not understand
+var ErrUnsupportedStateVersion = errors.New("unsupported Terraform state version")
@@ -0,0 +1,127 @@
+package terraform
+
+import "fmt"
+
+// instanceIndex maps EC2 instance IDs to their Terraform address and
+// normalized attributes. It is built once and only read afterwards, so it
+// is safe for concurrent use.
+type instanceIndex struct {
+	order []string
+	byID  map[string]indexedInstance
+}
+
+// indexedInstance is a single aws_instance in the index
+type indexedInstance struct {
+	address    string
+	attributes map[string]any
+	sensitive  []string // Dot paths of attributes Terraform marks as sensitive
+}
+
+func newInstanceIndex() *instanceIndex {
+	return &instanceIndex{
+		byID: make(map[string]indexedInstance),
+	}
+}
+
+// add indexes an instance by its id attribute. Instances without an ID are
+// skipped since they cannot be matched to AWS, and the first instance added
+// for an ID wins.
+func (idx *instanceIndex) add(address string, attrs map[string]any, sensitive []string) {
+	id, ok := attrs["id"].(string)
+	if !ok || id == "" {
+		return
+	}
+
+	if _, exists := idx.byID[id]; exists {
+		return
+	}
+	idx.order = append(idx.order, id)
+	idx.byID[id] = indexedInstance{
+		address:    address,
+		attributes: normalizeAttributes(attrs),
+		sensitive:  sensitive,
+	}
+}
+
+// lookup returns the indexed instance with the given ID
+func (idx *instanceIndex) lookup(instanceID string) (indexedInstance, error) {
+	instance, ok := idx.byID[instanceID]
+	if !ok {
+		return indexedInstance{}, fmt.Errorf("%w: %s", ErrInstanceNotInState, instanceID)
+	}
+	return instance, nil
+}
+
+// config returns a defensive copy of an instance's attributes
+func (idx *instanceIndex) config(instanceID string) (map[string]any, error) {
+	instance, err := idx.lookup(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return copyAttributes(instance.attributes), nil
+}
+
+// address returns the Terraform address of an instance
+func (idx *instanceIndex) address(instanceID string) (string, error) {
+	instance, err := idx.lookup(instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.address, nil
+}
+
+// sensitive returns the sensitive attribute paths of an instance
+func (idx *instanceIndex) sensitive(instanceID string) ([]string, error) {
+	instance, err := idx.lookup(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(instance.sensitive))
+	copy(paths, instance.sensitive)
+	return paths, nil
+}
+
+// all returns defensive copies of every instance in index order
+func (idx *instanceIndex) all() []map[string]any {
+	instances := make([]map[string]any, 0, len(idx.order))
+	for _, id := range idx.order {
+		instances = append(instances, copyAttributes(idx.byID[id].attributes))
+	}
+	return instances
+}
+
+// ids returns every indexed instance ID in index order
+func (idx *instanceIndex) ids() []string {
+	ids := make([]string, len(idx.order))
+	copy(ids, idx.order)
+	return ids
+}
+
+// copyAttributes deep-copies decoded JSON attributes
+func copyAttributes(src map[string]any) map[string]any {
+	dst := make(map[string]any, len(src))
+	for k, v := range src {
+		dst[k] = copyValue(v)
+	}
+	return dst
+}
+
+func copyValue(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		return copyAttributes(v)
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case []string:
+		copied := make([]string, len(v))
+		copy(copied, v)
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,254 @@
+package terraform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// checkStateVersion converts a decoded state to the version 4 model,
+// upgrading legacy version 3 modules when needed
+func checkStateVersion(state *State, modules []ModuleV3) (*State, error) {
+	switch state.Version {
+	case 4:
+		return state, nil
+	case 3:
+		return upgradeV3(&StateV3{
+			Version:          state.Version,
+			TerraformVersion: state.TerraformVersion,
+			Modules:          modules,
+		}), nil
+	default:
+		return nil, fmt.Errorf("%w: %d (supported versions are 3 and 4)", ErrUnsupportedStateVersion, state.Version)
+	}
+}
+
+// upgradeV3 converts a version 3 state into the version 4 model
+func upgradeV3(legacy *StateV3) *State {
+	state := &State{
+		Version:          legacy.Version,
+		TerraformVersion: legacy.TerraformVersion,
+		Resources:        make([]Resource, 0),
+	}
+
+	for _, module := range legacy.Modules {
+		modulePath := legacyModuleAddress(module.Path)
+
+		keys := make([]string, 0, len(module.Resources))
+		for key := range module.Resources {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// Group count instances (aws_instance.web.0, aws_instance.web.1)
+		// under a single resource the way version 4 does
+		grouped := make(map[string]int)
+		for _, key := range keys {
+			legacyResource := module.Resources[key]
+			if legacyResource.Primary == nil {
+				continue
+			}
+
+			mode, resourceType, name, index := parseLegacyKey(key)
+			if legacyResource.Type != "" {
+				resourceType = legacyResource.Type
+			}
+
+			attributes := expandFlatmap(legacyResource.Primary.Attributes)
+			if _, ok := attributes["id"]; !ok && legacyResource.Primary.ID != "" {
+				attributes["id"] = legacyResource.Primary.ID
+			}
+
+			instance := ResourceInstance{Attributes: attributes}
+			if index >= 0 {
+				instance.IndexKey = index
+			}
+
+			groupKey := fmt.Sprintf("%s|%s|%s|%s", modulePath, mode, resourceType, name)
+			pos, exists := grouped[groupKey]
+			if !exists {
+				state.Resources = append(state.Resources, Resource{
+					Module:   modulePath,
+					Mode:     mode,
+					Type:     resourceType,
+					Name:     name,
+					Provider: legacyResource.Provider,
+				})
+				pos = len(state.Resources) - 1
+				grouped[groupKey] = pos
+			}
+			state.Resources[pos].Instances = append(state.Resources[pos].Instances, instance)
+		}
+	}
+
+	return state
+}
+
+// legacyModuleAddress converts a module path such as ["root", "web", "db"]
+// to a module address such as module.web.module.db
+func legacyModuleAddress(path []string) string {
+	if len(path) > 0 && path[0] == "root" {
+		path = path[1:]
+	}
+
+	parts := make([]string, len(path))
+	for i, name := range path {
+		parts[i] = "module." + name
+	}
+	return strings.Join(parts, ".")
+}
+
+// parseLegacyKey parses a version 3 resource key such as aws_instance.web,
+// aws_instance.web.2 or data.aws_ami.ubuntu. The index is -1 when absent.
+func parseLegacyKey(key string) (mode, resourceType, name string, index int) {
+	mode = "managed"
+	if strings.HasPrefix(key, "data.") {
+		mode = "data"
+		key = strings.TrimPrefix(key, "data.")
+	}
+
+	parts := strings.Split(key, ".")
+	index = -1
+	if len(parts) >= 3 {
+		if i, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			index = i
+			parts = parts[:len(parts)-1]
+		}
+	}
+
+	resourceType = parts[0]
+	if len(parts) > 1 {
+		name = strings.Join(parts[1:], ".")
+	}
+	return mode, resourceType, name, index
+}
+
+// expandFlatmap expands version 3 flattened attributes, where lists are
+// written as "name.#" plus "name.N" and maps as "name.%" plus "name.key",
+// into the nested values version 4 would hold. Version 3 stores every leaf
+// as a string. Top-level leaves are kept as strings, since only the
+// attribute schema knows whether "123" is a number or a key name; the
+// detector converts them by AttributeSchema.Type. Leaves inside lists and
+// blocks are converted back to numbers and bools; map values were always
+// strings.
+func expandFlatmap(flat map[string]string) map[string]any {
+	return expandFlatmapMap(flat, "", false)
+}
+
+func expandFlatmapMap(flat map[string]string, prefix string, typed bool) map[string]any {
+	result := make(map[string]any)
+
+	for key, value := range flat {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		if rest == "%" || rest == "#" || rest == "" {
+			continue
+		}
+
+		// Nested collections are recognised by their count marker. Anything
+		// else is a leaf, even if its key contains dots (e.g. tag names).
+		segment := rest
+		if i := strings.Index(rest, "."); i >= 0 {
+			segment = rest[:i]
+		}
+		if segment != rest && hasFlatmapMarker(flat, prefix+segment) {
+			if _, done := result[segment]; !done {
+				result[segment] = expandFlatmapValue(flat, prefix+segment)
+			}
+			continue
+		}
+
+		result[rest] = flatmapLeaf(value, typed)
+	}
+
+	return result
+}
+
+func expandFlatmapValue(flat map[string]string, key string) any {
+	if _, ok := flat[key+".#"]; ok {
+		return expandFlatmapList(flat, key)
+	}
+	if value, ok := flat[key]; ok {
+		return flatmapLeaf(value, true)
+	}
+	// Maps are marked with "%"; anything else is a nested block
+	_, isMap := flat[key+".%"]
+	return expandFlatmapMap(flat, key+".", !isMap)
+}
+
+// flatmapLeaf converts a version 3 leaf to the value JSON decoding gives
+// in version 4. Only canonical spellings are converted, so strings such as
+// "007" or "1.50" are kept as written.
+func flatmapLeaf(value string, typed bool) any {
+	if !typed {
+		return value
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || strconv.FormatFloat(f, 'f', -1, 64) != value {
+		return value
+	}
+	return f
+}
+
+func expandFlatmapList(flat map[string]string, key string) []any {
+	prefix := key + "."
+
+	seen := make(map[string]bool)
+	indices := make([]string, 0)
+	for k := range flat {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		index := k[len(prefix):]
+		if i := strings.Index(index, "."); i >= 0 {
+			index = index[:i]
+		}
+		if index == "#" || seen[index] {
+			continue
+		}
+		seen[index] = true
+		indices = append(indices, index)
+	}
+
+	// List indices and set hash codes are numeric; order them numerically
+	sort.Slice(indices, func(i, j int) bool {
+		a, errA := strconv.Atoi(indices[i])
+		b, errB := strconv.Atoi(indices[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return indices[i] < indices[j]
+	})
+
+	result := make([]any, 0, len(indices))
+	for _, index := range indices {
+		element := prefix + index
+		if value, ok := flat[element]; ok {
+			result = append(result, flatmapLeaf(value, true))
+			continue
+		}
+		result = append(result, expandFlatmapValue(flat, element))
+	}
+	return result
+}
+
+// hasFlatmapMarker reports whether key is a flattened list or map
+func hasFlatmapMarker(flat map[string]string, key string) bool {
+	if _, ok := flat[key+".#"]; ok {
+		return true
+	}
+	_, ok := flat[key+".%"]
+	return ok
+}
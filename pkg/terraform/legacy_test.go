@@ -0,0 +1,162 @@
+package terraform
+
+import (
+	"errors"
+	"testing"
+)
+
+const legacyState = `{
+  "version": 3,
+  "terraform_version": "0.11.14",
+  "serial": 12,
+  "modules": [
+    {
+      "path": ["root"],
+      "resources": {
+        "aws_instance.bastion": {
+          "type": "aws_instance",
+          "provider": "provider.aws",
+          "primary": {
+            "id": "i-bastion",
+            "attributes": {
+              "id": "i-bastion",
+              "instance_type": "t2.micro",
+              "monitoring": "false",
+              "key_name": "123",
+              "tags.%": "3",
+              "tags.Name": "bastion",
+              "tags.CostCenter": "0042",
+              "tags.kubernetes.io/role": "none",
+              "vpc_security_group_ids.#": "2",
+              "vpc_security_group_ids.1234567": "sg-aaa",
+              "vpc_security_group_ids.7654321": "sg-bbb",
+              "root_block_device.#": "1",
+              "root_block_device.0.volume_size": "8",
+              "root_block_device.0.delete_on_termination": "true"
+            }
+          }
+        }
+      }
+    },
+    {
+      "path": ["root", "web"],
+      "resources": {
+        "aws_instance.app.0": {
+          "type": "aws_instance",
+          "primary": {"id": "i-app0", "attributes": {"id": "i-app0", "instance_type": "t2.small"}}
+        },
+        "aws_instance.app.1": {
+          "type": "aws_instance",
+          "primary": {"id": "i-app1", "attributes": {"instance_type": "t2.small"}}
+        },
+        "data.aws_ami.ubuntu": {
+          "type": "aws_ami",
+          "primary": {"id": "ami-123", "attributes": {"id": "ami-123"}}
+        }
+      }
+    }
+  ]
+}`
+
+func TestStateParser_LegacyVersion3(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		var opts []StateParserOption
+		if streaming {
+			opts = append(opts, WithStreaming())
+		}
+		parser := NewStateParser(writeState(t, legacyState), opts...)
+
+		ids, err := parser.GetInstanceIDs()
+		if err != nil {
+			t.Fatalf("streaming=%v: expected no error, got %v", streaming, err)
+		}
+
+		if len(ids) != 3 {
+			t.Fatalf("streaming=%v: expected 3 instances, got %v", streaming, ids)
+		}
+
+		address, err := parser.GetInstanceAddress("i-app1")
+		if err != nil {
+			t.Fatalf("streaming=%v: expected no error, got %v", streaming, err)
+		}
+		if address != "module.web.aws_instance.app[1]" {
+			t.Errorf("streaming=%v: expected module.web.aws_instance.app[1], got %s", streaming, address)
+		}
+	}
+}
+
+func TestStateParser_LegacyAttributes(t *testing.T) {
+	parser := NewStateParser(writeState(t, legacyState))
+
+	config, err := parser.GetInstanceConfig("i-bastion")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags, ok := config["tags"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected tags to be a map, got %T", config["tags"])
+	}
+	if tags["Name"] != "bastion" || tags["kubernetes.io/role"] != "none" || tags["CostCenter"] != "0042" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	// Top-level leaves are left for the detector to type by schema
+	if config["monitoring"] != "false" || config["key_name"] != "123" {
+		t.Errorf("Expected top-level strings, got monitoring %#v and key_name %#v", config["monitoring"], config["key_name"])
+	}
+
+	groups, ok := config["vpc_security_group_ids"].([]any)
+	if !ok || len(groups) != 2 || groups[0] != "sg-aaa" || groups[1] != "sg-bbb" {
+		t.Errorf("Unexpected security groups %v", config["vpc_security_group_ids"])
+	}
+
+	devices, ok := config["root_block_device"].([]any)
+	if !ok || len(devices) != 1 {
+		t.Fatalf("Expected one root block device, got %v", config["root_block_device"])
+	}
+	if device, _ := devices[0].(map[string]any); device["volume_size"] != float64(8) || device["delete_on_termination"] != true {
+		t.Errorf("Unexpected root block device %#v", devices[0])
+	}
+}
+
+func TestFlatmapLeaf(t *testing.T) {
+	tests := []struct {
+		value    string
+		typed    bool
+		expected any
+	}{
+		{"true", true, true},
+		{"false", true, false},
+		{"8", true, float64(8)},
+		{"-1.5", true, -1.5},
+		{"007", true, "007"},
+		{"1.50", true, "1.50"},
+		{"1e3", true, "1e3"},
+		{"NaN", true, "NaN"},
+		{"t3.micro", true, "t3.micro"},
+		{"true", false, "true"},
+		{"8", false, "8"},
+	}
+
+	for _, tt := range tests {
+		if got := flatmapLeaf(tt.value, tt.typed); got != tt.expected {
+			t.Errorf("flatmapLeaf(%q, %v): expected %#v, got %#v", tt.value, tt.typed, tt.expected, got)
+		}
+	}
+}
+
+func TestStateParser_UnsupportedVersion(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		var opts []StateParserOption
+		if streaming {
+			opts = append(opts, WithStreaming())
+		}
+		parser := NewStateParser(writeState(t, `{"version": 2, "modules": []}`), opts...)
+
+		_, err := parser.GetInstanceIDs()
+		if !errors.Is(err, ErrUnsupportedStateVersion) {
+			t.Errorf("streaming=%v: expected ErrUnsupportedStateVersion, got %v", streaming, err)
+		}
+	}
+}
@@ -5,4 +5,6 @@ type Parser interface {
 	GetInstanceConfig(instanceID string) (map[string]any, error)
 	GetAllInstances() ([]map[string]any, error)
 	GetInstanceIDs() ([]string, error)
-}
\ No newline at end of file
+	GetInstanceAddress(instanceID string) (string, error)
+	GetSensitiveAttributes(instanceID string) ([]string, error)
+}
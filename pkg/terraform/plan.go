@@ -0,0 +1,163 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// PlanParser parses the JSON output of `terraform show -json <planfile>` and
+// exposes the configured (planned) values of aws_instance resources, so drift
+// is measured against the intended configuration rather than the last apply.
+// Like StateParser it is safe for concurrent use.
+type PlanParser struct {
+	planPath string
+
+	loadOnce sync.Once
+	loadErr  error
+	index    *instanceIndex
+}
+
+// NewPlanParser creates a new Terraform plan JSON parser
+func NewPlanParser(planPath string) *PlanParser {
+	return &PlanParser{
+		planPath: planPath,
+	}
+}
+
+// Source returns the path of the plan file
+func (p *PlanParser) Source() string {
+	return p.planPath
+}
+
+// loadPlan loads the plan file and indexes intended instance values once
+func (p *PlanParser) loadPlan() error {
+	p.loadOnce.Do(func() {
+		p.index, p.loadErr = p.readPlan()
+	})
+	return p.loadErr
+}
+
+func (p *PlanParser) readPlan() (*instanceIndex, error) {
+	data, err := os.ReadFile(p.planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+
+	return indexPlan(&plan), nil
+}
+
+// indexPlan merges prior_state and resource_changes into the intended
+// values of every aws_instance that already exists
+func indexPlan(plan *Plan) *instanceIndex {
+	index := newInstanceIndex()
+	seen := make(map[string]bool)
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode != "managed" || rc.Type != "aws_instance" {
+			continue
+		}
+		seen[rc.Address] = true
+
+		// Instances being destroyed have no intended configuration and
+		// instances being created have no ID to compare against yet
+		if slices.Equal(rc.Change.Actions, []string{"delete"}) || rc.Change.Before == nil {
+			continue
+		}
+
+		sensitive := mergePaths(sensitiveValuePaths(rc.Change.BeforeSensitive), sensitiveValuePaths(rc.Change.AfterSensitive))
+		index.add(rc.Address, intendedValues(rc.Change), sensitive)
+	}
+
+	// Fall back to prior state for instances without a change entry
+	if plan.PriorState != nil && plan.PriorState.Values != nil {
+		for _, resource := range priorResources(plan.PriorState.Values.RootModule) {
+			if resource.Mode != "managed" || resource.Type != "aws_instance" || seen[resource.Address] {
+				continue
+			}
+			index.add(resource.Address, resource.Values, sensitiveValuePaths(resource.SensitiveValues))
+		}
+	}
+
+	return index
+}
+
+// intendedValues overlays the known planned values on the current values.
+// Values Terraform cannot know until apply keep their current value.
+func intendedValues(change Change) map[string]any {
+	values := make(map[string]any, len(change.Before))
+	for k, v := range change.Before {
+		values[k] = v
+	}
+
+	for k, v := range change.After {
+		if unknown, ok := change.AfterUnknown[k].(bool); ok && unknown {
+			continue
+		}
+		values[k] = v
+	}
+
+	// A replaced instance keeps its current ID until apply
+	if id, ok := change.Before["id"]; ok {
+		values["id"] = id
+	}
+
+	return values
+}
+
+// priorResources flattens the resources of a module tree
+func priorResources(module PlanModule) []PlanResource {
+	resources := append([]PlanResource{}, module.Resources...)
+	for _, child := range module.ChildModules {
+		resources = append(resources, priorResources(child)...)
+	}
+	return resources
+}
+
+// GetInstanceConfig retrieves the intended configuration for a specific EC2 instance
+func (p *PlanParser) GetInstanceConfig(instanceID string) (map[string]any, error) {
+	if err := p.loadPlan(); err != nil {
+		return nil, err
+	}
+	return p.index.config(instanceID)
+}
+
+// GetInstanceAddress returns the full Terraform address of a specific EC2 instance
+func (p *PlanParser) GetInstanceAddress(instanceID string) (string, error) {
+	if err := p.loadPlan(); err != nil {
+		return "", err
+	}
+	return p.index.address(instanceID)
+}
+
+// GetSensitiveAttributes returns the attribute paths the plan marks as
+// sensitive for a specific EC2 instance
+func (p *PlanParser) GetSensitiveAttributes(instanceID string) ([]string, error) {
+	if err := p.loadPlan(); err != nil {
+		return nil, err
+	}
+	return p.index.sensitive(instanceID)
+}
+
+// GetAllInstances returns the intended configuration of all EC2 instances in the plan
+func (p *PlanParser) GetAllInstances() ([]map[string]any, error) {
+	if err := p.loadPlan(); err != nil {
+		return nil, err
+	}
+	return p.index.all(), nil
+}
+
+// GetInstanceIDs returns all EC2 instance IDs from the plan
+func (p *PlanParser) GetInstanceIDs() ([]string, error) {
+	if err := p.loadPlan(); err != nil {
+		return nil, err
+	}
+	return p.index.ids(), nil
+}
@@ -0,0 +1,138 @@
+package terraform
+
+import "testing"
+
+const planJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.6.0",
+  "prior_state": {
+    "format_version": "1.0",
+    "values": {
+      "root_module": {
+        "resources": [
+          {
+            "address": "aws_instance.web",
+            "mode": "managed",
+            "type": "aws_instance",
+            "name": "web",
+            "values": {"id": "i-web", "instance_type": "t3.small", "ami": "ami-old"}
+          }
+        ],
+        "child_modules": [
+          {
+            "address": "module.batch",
+            "resources": [
+              {
+                "address": "module.batch.aws_instance.worker[0]",
+                "mode": "managed",
+                "type": "aws_instance",
+                "name": "worker",
+                "index": 0,
+                "values": {"id": "i-worker", "instance_type": "c5.large"}
+              }
+            ]
+          }
+        ]
+      }
+    }
+  },
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": "i-web", "instance_type": "t3.small", "ami": "ami-old", "public_ip": "1.2.3.4"},
+        "after": {"id": "i-web", "instance_type": "t3.large", "ami": "ami-old", "public_ip": null},
+        "after_unknown": {"public_ip": true},
+        "before_sensitive": {"user_data": true},
+        "after_sensitive": {"tags": {"Secret": true}}
+      }
+    },
+    {
+      "address": "aws_instance.old",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "old",
+      "change": {
+        "actions": ["delete"],
+        "before": {"id": "i-old", "instance_type": "t2.micro"},
+        "after": null
+      }
+    },
+    {
+      "address": "aws_instance.new",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "new",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"instance_type": "t3.micro"},
+        "after_unknown": {"id": true}
+      }
+    }
+  ]
+}`
+
+func TestPlanParser_IntendedValues(t *testing.T) {
+	parser := NewPlanParser(writeState(t, planJSON))
+
+	config, err := parser.GetInstanceConfig("i-web")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config["instance_type"] != "t3.large" {
+		t.Errorf("Expected planned instance_type t3.large, got %v", config["instance_type"])
+	}
+
+	if config["public_ip"] != "1.2.3.4" {
+		t.Errorf("Expected unknown public_ip to keep current value, got %v", config["public_ip"])
+	}
+}
+
+func TestPlanParser_InstanceIDs(t *testing.T) {
+	parser := NewPlanParser(writeState(t, planJSON))
+
+	ids, err := parser.GetInstanceIDs()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] != "i-web" || ids[1] != "i-worker" {
+		t.Errorf("Expected [i-web i-worker], got %v", ids)
+	}
+
+	address, err := parser.GetInstanceAddress("i-worker")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if address != "module.batch.aws_instance.worker[0]" {
+		t.Errorf("Expected module.batch.aws_instance.worker[0], got %s", address)
+	}
+}
+
+func TestPlanParser_DestroyedInstance(t *testing.T) {
+	parser := NewPlanParser(writeState(t, planJSON))
+
+	if _, err := parser.GetInstanceConfig("i-old"); err == nil {
+		t.Errorf("Expected destroyed instance to have no intended configuration")
+	}
+}
+
+func TestPlanParser_GetSensitiveAttributes(t *testing.T) {
+	parser := NewPlanParser(writeState(t, planJSON))
+
+	paths, err := parser.GetSensitiveAttributes("i-web")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "tags.Secret" || paths[1] != "user_data" {
+		t.Errorf("Expected [tags.Secret user_data], got %v", paths)
+	}
+}
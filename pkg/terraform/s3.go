@@ -0,0 +1,86 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// defaultWorkspaceKeyPrefix matches the S3 backend's workspace_key_prefix default
+const defaultWorkspaceKeyPrefix = "env:"
+
+// S3Backend describes a Terraform S3 backend
+type S3Backend struct {
+	Bucket             string
+	Key                string
+	Region             string
+	Workspace          string // Empty or "default" selects the default workspace
+	WorkspaceKeyPrefix string // Defaults to "env:"
+	Endpoint           string // Optional endpoint for S3-compatible stores
+	UsePathStyle       bool
+}
+
+// ObjectKey returns the key of the state object for the configured
+// workspace, following the S3 backend's layout
+func (b S3Backend) ObjectKey() string {
+	if b.Workspace == "" || b.Workspace == "default" {
+		return b.Key
+	}
+
+	prefix := b.WorkspaceKeyPrefix
+	if prefix == "" {
+		prefix = defaultWorkspaceKeyPrefix
+	}
+	return fmt.Sprintf("%s/%s/%s", prefix, b.Workspace, b.Key)
+}
+
+// S3GetObjectAPI is the part of the S3 client used to read state. The
+// parser only ever reads: it never writes state and never takes the
+// DynamoDB lock, so it is safe to run alongside terraform apply.
+type S3GetObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// NewS3Client creates an S3 client for the backend using the default AWS
+// credential chain
+func NewS3Client(ctx context.Context, backend S3Backend) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(backend.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if backend.Endpoint != "" {
+			o.BaseEndpoint = awssdk.String(backend.Endpoint)
+		}
+		o.UsePathStyle = backend.UsePathStyle
+	}), nil
+}
+
+// NewS3StateParser creates a state parser that reads the state object
+// directly from an S3 backend. The object is fetched and parsed before it
+// returns, so ctx only bounds the download and is not retained.
+func NewS3StateParser(ctx context.Context, client S3GetObjectAPI, backend S3Backend, opts ...StateParserOption) (*StateParser, error) {
+	key := backend.ObjectKey()
+
+	open := func() (io.ReadCloser, error) {
+		output, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: awssdk.String(backend.Bucket),
+			Key:    awssdk.String(key),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get s3://%s/%s: %w", backend.Bucket, key, err)
+		}
+		return output.Body, nil
+	}
+
+	p := newStateParser(fmt.Sprintf("s3://%s/%s", backend.Bucket, key), open, opts)
+	if err := p.loadState(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,150 @@
+package terraform
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// fakeS3 is a minimal S3-compatible stand-in serving objects over path-style URLs
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string]string
+	requests []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	body, exists := f.objects[r.URL.Path]
+	if r.Method != http.MethodGet || !exists {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+		return
+	}
+
+	_, _ = w.Write([]byte(body))
+}
+
+func newS3TestParser(t *testing.T, backend S3Backend, objects map[string]string) (*StateParser, *fakeS3, error) {
+	t.Helper()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	fake := &fakeS3{objects: objects}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	backend.Endpoint = server.URL
+	backend.UsePathStyle = true
+
+	client, err := NewS3Client(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("Failed to create S3 client: %v", err)
+	}
+
+	parser, err := NewS3StateParser(context.Background(), client, backend)
+	return parser, fake, err
+}
+
+func TestS3StateParser_Workspace(t *testing.T) {
+	backend := S3Backend{
+		Bucket:    "tf-state",
+		Key:       "network/terraform.tfstate",
+		Region:    "us-east-1",
+		Workspace: "staging",
+	}
+
+	parser, fake, err := newS3TestParser(t, backend, map[string]string{
+		"/tf-state/env:/staging/network/terraform.tfstate": moduleState,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	address, err := parser.GetInstanceAddress("i-blue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if address != `module.web.aws_instance.app["blue"]` {
+		t.Errorf("Unexpected address %s", address)
+	}
+
+	if parser.Source() != "s3://tf-state/env:/staging/network/terraform.tfstate" {
+		t.Errorf("Unexpected source %s", parser.Source())
+	}
+
+	// Only a single read of the state object is allowed: no writes, no lock files
+	if len(fake.requests) != 1 || fake.requests[0] != "GET /tf-state/env:/staging/network/terraform.tfstate" {
+		t.Errorf("Expected a single GET of the state object, got %v", fake.requests)
+	}
+}
+
+func TestS3StateParser_MissingObject(t *testing.T) {
+	backend := S3Backend{Bucket: "tf-state", Key: "missing.tfstate", Region: "us-east-1"}
+
+	if _, _, err := newS3TestParser(t, backend, map[string]string{}); err == nil {
+		t.Errorf("Expected error for missing state object")
+	}
+}
+
+// cancelAfterGet fails like the SDK once its context is cancelled
+type cancelAfterGet struct {
+	body string
+}
+
+func (c cancelAfterGet) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(c.body))}, nil
+}
+
+func TestS3StateParser_ContextNotRetained(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backend := S3Backend{Bucket: "tf-state", Key: "app.tfstate"}
+
+	parser, err := NewS3StateParser(ctx, cancelAfterGet{body: moduleState}, backend)
+	cancel()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := parser.GetInstanceAddress("i-blue"); err != nil {
+		t.Errorf("Expected state to be loaded before the context was cancelled, got %v", err)
+	}
+}
+
+func TestS3Backend_ObjectKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  S3Backend
+		expected string
+	}{
+		{"default workspace", S3Backend{Key: "app.tfstate"}, "app.tfstate"},
+		{"named default", S3Backend{Key: "app.tfstate", Workspace: "default"}, "app.tfstate"},
+		{"workspace", S3Backend{Key: "app.tfstate", Workspace: "prod"}, "env:/prod/app.tfstate"},
+		{"custom prefix", S3Backend{Key: "app.tfstate", Workspace: "prod", WorkspaceKeyPrefix: "workspaces"}, "workspaces/prod/app.tfstate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := tt.backend.ObjectKey(); key != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, key)
+			}
+		})
+	}
+}
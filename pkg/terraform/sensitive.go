@@ -0,0 +1,106 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pathStep is a single step of a sensitive attribute path in state, e.g.
+// {"type": "get_attr", "value": "tags"} or
+// {"type": "index", "value": {"value": "Secret", "type": "string"}}
+type pathStep struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// sensitivePaths converts a state instance's sensitive_attributes into dot
+// paths such as user_data or tags.Secret. Unrecognised formats are ignored
+// rather than failing the whole state.
+func sensitivePaths(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var paths [][]pathStep
+	if err := json.Unmarshal(raw, &paths); err != nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(paths))
+	for _, steps := range paths {
+		parts := make([]string, 0, len(steps))
+		for _, step := range steps {
+			switch step.Type {
+			case "get_attr":
+				parts = append(parts, fmt.Sprintf("%v", step.Value))
+			case "index":
+				if key, ok := step.Value.(map[string]any); ok {
+					parts = append(parts, formatPathKey(key["value"]))
+				}
+			}
+		}
+		if len(parts) > 0 {
+			result = append(result, strings.Join(parts, "."))
+		}
+	}
+
+	return result
+}
+
+// sensitiveValuePaths converts a plan's sensitive value mask, which mirrors
+// the attribute structure with true at sensitive leaves, into dot paths
+func sensitiveValuePaths(mask any) []string {
+	paths := make([]string, 0)
+	collectSensitivePaths(mask, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func collectSensitivePaths(mask any, prefix string, paths *[]string) {
+	switch m := mask.(type) {
+	case bool:
+		if m && prefix != "" {
+			*paths = append(*paths, prefix)
+		}
+	case map[string]any:
+		for key, value := range m {
+			collectSensitivePaths(value, joinPath(prefix, key), paths)
+		}
+	case []any:
+		for i, value := range m {
+			collectSensitivePaths(value, joinPath(prefix, fmt.Sprintf("%d", i)), paths)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func formatPathKey(key any) string {
+	if f, ok := key.(float64); ok && f == float64(int64(f)) {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return fmt.Sprintf("%v", key)
+}
+
+// mergePaths returns the sorted union of path lists
+func mergePaths(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, list := range lists {
+		for _, path := range list {
+			if !seen[path] {
+				seen[path] = true
+				merged = append(merged, path)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,63 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sensitiveState = `{
+  "version": 4,
+  "terraform_version": "1.6.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "app",
+      "instances": [
+        {
+          "schema_version": 1,
+          "attributes": {"id": "i-app", "user_data": "secret", "tags": {"Secret": "x", "Name": "app"}},
+          "sensitive_attributes": [
+            [{"type": "get_attr", "value": "user_data"}],
+            [{"type": "get_attr", "value": "tags"}, {"type": "index", "value": {"value": "Secret", "type": "string"}}]
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestStateParser_GetSensitiveAttributes(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		var opts []StateParserOption
+		if streaming {
+			opts = append(opts, WithStreaming())
+		}
+		parser := NewStateParser(writeState(t, sensitiveState), opts...)
+
+		paths, err := parser.GetSensitiveAttributes("i-app")
+		if err != nil {
+			t.Fatalf("streaming=%v: expected no error, got %v", streaming, err)
+		}
+
+		expected := []string{"user_data", "tags.Secret"}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("streaming=%v: expected %v, got %v", streaming, expected, paths)
+		}
+	}
+}
+
+func TestSensitiveValuePaths(t *testing.T) {
+	mask := map[string]any{
+		"user_data": true,
+		"tags":      map[string]any{"Secret": true, "Name": false},
+		"ebs_block_device": []any{
+			map[string]any{"kms_key_id": true},
+		},
+	}
+
+	expected := []string{"ebs_block_device.0.kms_key_id", "tags.Secret", "user_data"}
+	if paths := sensitiveValuePaths(mask); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
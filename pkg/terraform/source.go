@@ -0,0 +1,77 @@
+package terraform
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SourceLocation is where a resource block is declared in configuration
+type SourceLocation struct {
+	File string
+	Line int
+}
+
+// SourceIndex maps root module resources ("aws_instance.web") to the .tf
+// file and line declaring them
+type SourceIndex map[string]SourceLocation
+
+var resourceBlockPattern = regexp.MustCompile(`^\s*resource\s+"([^"]+)"\s+"([^"]+)"`)
+
+// LoadSources scans the .tf files in a configuration directory for resource
+// blocks. Only the root module is scanned; resources declared in child
+// modules are not located.
+func LoadSources(dir string) (SourceIndex, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Terraform files: %w", err)
+	}
+
+	index := make(SourceIndex)
+	for _, file := range files {
+		if err := index.scanFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+func (idx SourceIndex) scanFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open Terraform file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		match := resourceBlockPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		idx[match[1]+"."+match[2]] = SourceLocation{File: path, Line: line}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read Terraform file: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the declaration of the resource an instance address
+// belongs to, e.g. aws_instance.web["blue"] resolves to aws_instance.web
+func (idx SourceIndex) Lookup(address string) (SourceLocation, bool) {
+	if strings.HasPrefix(address, "module.") || strings.HasPrefix(address, "data.") {
+		return SourceLocation{}, false
+	}
+
+	if i := strings.Index(address, "["); i >= 0 {
+		address = address[:i]
+	}
+
+	location, ok := idx[address]
+	return location, ok
+}
@@ -0,0 +1,48 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSources(t *testing.T) {
+	dir := t.TempDir()
+	config := `provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_instance" "web" {
+  count = 2
+}
+
+  resource "aws_instance" "api" {
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(config), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	sources, err := LoadSources(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		address string
+		line    int
+		found   bool
+	}{
+		{"aws_instance.web[1]", 5, true},
+		{"aws_instance.api", 9, true},
+		{"module.app.aws_instance.web[0]", 0, false},
+		{"aws_instance.missing", 0, false},
+	}
+
+	for _, tt := range tests {
+		location, ok := sources.Lookup(tt.address)
+		if ok != tt.found || location.Line != tt.line {
+			t.Errorf("%s: expected line %d (found=%v), got %+v (found=%v)", tt.address, tt.line, tt.found, location, ok)
+		}
+	}
+}
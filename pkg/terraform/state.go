@@ -3,40 +3,128 @@ package terraform
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
-// StateParser parses Terraform state files
+// StateParser parses Terraform state files. The state is loaded and indexed
+// once on first use; after that all methods are safe for concurrent use.
 type StateParser struct {
 	statePath string
-	state     *State
+	open      func() (io.ReadCloser, error)
+
+	streaming bool
+
+	loadOnce sync.Once
+	loadErr  error
+	index    *instanceIndex
+}
+
+// StateParserOption configures optional StateParser behaviour
+type StateParserOption func(*StateParser)
+
+// WithStreaming decodes the state token by token, materializing only
+// aws_instance resources. This keeps memory proportional to the largest
+// aws_instance resource rather than the whole file.
+func WithStreaming() StateParserOption {
+	return func(p *StateParser) {
+		p.streaming = true
+	}
 }
 
 // NewStateParser creates a new Terraform state parser
-func NewStateParser(statePath string) *StateParser {
-	return &StateParser{
+func NewStateParser(statePath string, opts ...StateParserOption) *StateParser {
+	return newStateParser(statePath, func() (io.ReadCloser, error) {
+		return os.Open(statePath)
+	}, opts)
+}
+
+func newStateParser(statePath string, open func() (io.ReadCloser, error), opts []StateParserOption) *StateParser {
+	p := &StateParser{
 		statePath: statePath,
+		open:      open,
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Source returns a description of where the state is read from
+func (p *StateParser) Source() string {
+	return p.statePath
 }
 
-// loadState loads and parses the Terraform state file
+// loadState loads, parses and indexes the Terraform state file exactly once
 func (p *StateParser) loadState() error {
-	if p.state != nil {
-		return nil
+	p.loadOnce.Do(func() {
+		p.index, p.loadErr = p.readState()
+	})
+	return p.loadErr
+}
+
+// readState reads the state and builds the instance index
+func (p *StateParser) readState() (*instanceIndex, error) {
+	reader, err := p.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
+	defer reader.Close()
 
-	data, err := os.ReadFile(p.statePath)
+	if p.streaming {
+		state, modules, err := decodeStateStream(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %w", err)
+		}
+		state, err = checkStateVersion(state, modules)
+		if err != nil {
+			return nil, err
+		}
+		return indexState(state), nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	// Version 3 keeps resources under modules; version 4 under resources
+	var decoded struct {
+		State
+		Modules []ModuleV3 `json:"modules"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	state, err := checkStateVersion(&decoded.State, decoded.Modules)
 	if err != nil {
-		return fmt.Errorf("failed to read state file: %w", err)
+		return nil, err
 	}
 
-	var state State
-	if err := json.Unmarshal(data, &state); err != nil {
-		return fmt.Errorf("failed to parse state file: %w", err)
+	return indexState(state), nil
+}
+
+// indexState indexes every managed aws_instance in the state by EC2 ID.
+// Data sources only read instances, so they neither define nor manage them.
+// Resources without a mode, as in hand-written states, count as managed.
+func indexState(state *State) *instanceIndex {
+	index := newInstanceIndex()
+
+	for _, resource := range state.Resources {
+		managed := resource.Mode == "" || resource.Mode == "managed"
+		if !managed || resource.Type != "aws_instance" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			index.add(resource.Address(instance), instance.Attributes, sensitivePaths(instance.SensitiveAttributes))
+		}
 	}
 
-	p.state = &state
-	return nil
+	return index
 }
 
 // GetInstanceConfig retrieves configuration for a specific EC2 instance
@@ -44,22 +132,29 @@ func (p *StateParser) GetInstanceConfig(instanceID string) (map[string]any, erro
 	if err := p.loadState(); err != nil {
 		return nil, err
 	}
+	return p.index.config(instanceID)
+}
 
-	// Find the EC2 instance resource
-	for _, resource := range p.state.Resources {
-		if resource.Type == "aws_instance" {
-			for _, instance := range resource.Instances {
-				if id, ok := instance.Attributes["id"].(string); ok && id == instanceID {
-					return p.normalizeAttributes(instance.Attributes), nil
-				}
-			}
-		}
+// GetInstanceAddress returns the full Terraform address of a specific EC2 instance
+func (p *StateParser) GetInstanceAddress(instanceID string) (string, error) {
+	if err := p.loadState(); err != nil {
+		return "", err
+	}
+	return p.index.address(instanceID)
+}
+
+// GetSensitiveAttributes returns the attribute paths Terraform marks as
+// sensitive for a specific EC2 instance
+func (p *StateParser) GetSensitiveAttributes(instanceID string) ([]string, error) {
+	if err := p.loadState(); err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("instance %s not found in Terraform state", instanceID)
+	return p.index.sensitive(instanceID)
 }
 
-// normalizeAttributes normalizes Terraform attributes to match AWS format
-func (p *StateParser) normalizeAttributes(attrs map[string]any) map[string]any {
+// normalizeAttributes performs type-level cleanup of decoded JSON values.
+// Attribute-specific normalization lives in the detector's attribute registry.
+func normalizeAttributes(attrs map[string]any) map[string]any {
 	normalized := make(map[string]any)
 
 	// Copy all attributes
@@ -67,16 +162,6 @@ func (p *StateParser) normalizeAttributes(attrs map[string]any) map[string]any {
 		normalized[k] = v
 	}
 
-	// Security groups - convert to string slice
-	if sgIDs, ok := attrs["vpc_security_group_ids"]; ok {
-		normalized["vpc_security_group_ids"] = convertToStringSlice(sgIDs)
-	}
-
-	// Tags - ensure proper format
-	if tags, ok := attrs["tags"].(map[string]any); ok {
-		normalized["tags"] = tags
-	}
-
 	// Convert float64 to int64 where appropriate
 	for k, v := range normalized {
 		if f, ok := v.(float64); ok {
@@ -89,58 +174,18 @@ func (p *StateParser) normalizeAttributes(attrs map[string]any) map[string]any {
 	return normalized
 }
 
-// convertToStringSlice converts various types to []string
-func convertToStringSlice(val any) []string {
-	switch v := val.(type) {
-	case []string:
-		return v
-	case []any:
-		result := make([]string, 0, len(v))
-		for _, item := range v {
-			if str, ok := item.(string); ok {
-				result = append(result, str)
-			}
-		}
-		return result
-	case string:
-		return []string{v}
-	default:
-		return []string{}
-	}
-}
-
 // GetAllInstances returns all EC2 instances from the state
 func (p *StateParser) GetAllInstances() ([]map[string]any, error) {
 	if err := p.loadState(); err != nil {
 		return nil, err
 	}
-
-	instances := make([]map[string]any, 0)
-
-	for _, resource := range p.state.Resources {
-		if resource.Type == "aws_instance" {
-			for _, instance := range resource.Instances {
-				instances = append(instances, p.normalizeAttributes(instance.Attributes))
-			}
-		}
-	}
-
-	return instances, nil
+	return p.index.all(), nil
 }
 
 // GetInstanceIDs returns all EC2 instance IDs from the state
 func (p *StateParser) GetInstanceIDs() ([]string, error) {
-	instances, err := p.GetAllInstances()
-	if err != nil {
+	if err := p.loadState(); err != nil {
 		return nil, err
 	}
-
-	ids := make([]string, 0, len(instances))
-	for _, instance := range instances {
-		if id, ok := instance["id"].(string); ok {
-			ids = append(ids, id)
-		}
-	}
-
-	return ids, nil
-}
\ No newline at end of file
+	return p.index.ids(), nil
+}
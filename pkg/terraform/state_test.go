@@ -0,0 +1,238 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const moduleState = `{
+  "version": 4,
+  "terraform_version": "1.6.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "single",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"id": "i-single", "instance_type": "t3.micro"}}
+      ]
+    },
+    {
+      "module": "module.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "app",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": "blue", "schema_version": 1, "attributes": {"id": "i-blue", "instance_type": "t3.small"}},
+        {"index_key": "green", "schema_version": 1, "attributes": {"id": "i-green", "instance_type": "t3.small"}}
+      ]
+    },
+    {
+      "module": "module.batch[0]",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "worker",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "schema_version": 1, "attributes": {"id": "i-worker0", "instance_type": "c5.large"}},
+        {"index_key": 1, "schema_version": 1, "attributes": {"id": "i-worker1", "instance_type": "c5.large"}}
+      ]
+    }
+  ]
+}`
+
+func writeState(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+	return path
+}
+
+func TestStateParser_GetInstanceAddress(t *testing.T) {
+	parser := NewStateParser(writeState(t, moduleState))
+
+	tests := []struct {
+		name       string
+		instanceID string
+		expected   string
+	}{
+		{"root module", "i-single", "aws_instance.single"},
+		{"for_each in module", "i-blue", `module.web.aws_instance.app["blue"]`},
+		{"count in indexed module", "i-worker1", "module.batch[0].aws_instance.worker[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := parser.GetInstanceAddress(tt.instanceID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if address != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, address)
+			}
+		})
+	}
+}
+
+func TestStateParser_GetInstanceAddress_NotFound(t *testing.T) {
+	parser := NewStateParser(writeState(t, moduleState))
+
+	if _, err := parser.GetInstanceAddress("i-missing"); err == nil {
+		t.Errorf("Expected error for unknown instance")
+	}
+}
+
+func TestStateParser_GetInstanceIDs_Modules(t *testing.T) {
+	parser := NewStateParser(writeState(t, moduleState))
+
+	ids, err := parser.GetInstanceIDs()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ids) != 5 {
+		t.Errorf("Expected 5 instance IDs, got %d", len(ids))
+	}
+}
+
+func TestStateParser_ConcurrentAccess(t *testing.T) {
+	parser := NewStateParser(writeState(t, moduleState))
+	ids := []string{"i-single", "i-blue", "i-green", "i-worker0", "i-worker1"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			config, err := parser.GetInstanceConfig(id)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			// Callers may freely modify the returned map
+			config["instance_type"] = "modified"
+		}(ids[i%len(ids)])
+	}
+	wg.Wait()
+
+	config, err := parser.GetInstanceConfig("i-single")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config["instance_type"] != "t3.micro" {
+		t.Errorf("Expected parser state to be unaffected by callers, got %v", config["instance_type"])
+	}
+}
+
+// dataSourceState looks up the managed instance, and an unmanaged one, with
+// data sources declared after the resource
+const dataSourceState = `{
+  "version": 4,
+  "terraform_version": "1.6.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"id": "i-web", "instance_type": "t3.micro"}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"id": "i-web", "instance_type": "t3.large"}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_instance",
+      "name": "bastion",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"id": "i-bastion", "instance_type": "t3.nano"}}
+      ]
+    }
+  ]
+}`
+
+func TestStateParser_IgnoresDataSources(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []StateParserOption
+	}{
+		{"buffered", nil},
+		{"streaming", []StateParserOption{WithStreaming()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewStateParser(writeState(t, dataSourceState), tt.opts...)
+
+			address, err := parser.GetInstanceAddress("i-web")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if address != "aws_instance.web" {
+				t.Errorf("Expected the managed address, got %s", address)
+			}
+
+			config, err := parser.GetInstanceConfig("i-web")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config["instance_type"] != "t3.micro" {
+				t.Errorf("Expected managed attributes, got instance_type %v", config["instance_type"])
+			}
+
+			ids, err := parser.GetInstanceIDs()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(ids) != 1 || ids[0] != "i-web" {
+				t.Errorf("Expected only the managed instance, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestStateParser_ResourcesWithoutMode(t *testing.T) {
+	state := `{"version": 4, "resources": [
+	  {"type": "aws_instance", "name": "web", "instances": [{"attributes": {"id": "i-web"}}]},
+	  {"mode": "data", "type": "aws_instance", "name": "lookup", "instances": [{"attributes": {"id": "i-lookup"}}]}
+	]}`
+
+	for _, streaming := range []bool{false, true} {
+		var opts []StateParserOption
+		if streaming {
+			opts = append(opts, WithStreaming())
+		}
+		parser := NewStateParser(writeState(t, state), opts...)
+
+		ids, err := parser.GetInstanceIDs()
+		if err != nil {
+			t.Fatalf("streaming=%v: expected no error, got %v", streaming, err)
+		}
+		if len(ids) != 1 || ids[0] != "i-web" {
+			t.Errorf("streaming=%v: expected the resource without a mode to be managed, got %v", streaming, ids)
+		}
+
+		address, err := parser.GetInstanceAddress("i-web")
+		if err != nil || address != "aws_instance.web" {
+			t.Errorf("streaming=%v: expected aws_instance.web, got %q (%v)", streaming, address, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// streamResource is a state resource whose instances are left undecoded
+// until the resource type is known to be wanted
+type streamResource struct {
+	Module    string          `json:"module,omitempty"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Provider  string          `json:"provider"`
+	Instances json.RawMessage `json:"instances"`
+}
+
+// decodeStateStream walks a state document token by token and only
+// materializes aws_instance resources, the only ones indexState keeps.
+// Legacy version 3 modules are returned as-is; those states predate large
+// monoliths.
+func decodeStateStream(r io.Reader) (*State, []ModuleV3, error) {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, nil, err
+	}
+
+	var state State
+	var modules []ModuleV3
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected token %v", token)
+		}
+
+		switch key {
+		case "version":
+			err = dec.Decode(&state.Version)
+		case "terraform_version":
+			err = dec.Decode(&state.TerraformVersion)
+		case "resources":
+			state.Resources, err = decodeResourcesStream(dec)
+		case "modules":
+			err = dec.Decode(&modules)
+		default:
+			// Skip outputs, check_results and anything else we do not need
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %q: %w", key, err)
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, nil, err
+	}
+
+	return &state, modules, nil
+}
+
+// decodeResourcesStream decodes the resources array one element at a time
+func decodeResourcesStream(dec *json.Decoder) ([]Resource, error) {
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0)
+	for dec.More() {
+		var raw streamResource
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if raw.Type != "aws_instance" {
+			continue
+		}
+
+		resource := Resource{
+			Module:   raw.Module,
+			Mode:     raw.Mode,
+			Type:     raw.Type,
+			Name:     raw.Name,
+			Provider: raw.Provider,
+		}
+		if len(raw.Instances) > 0 {
+			if err := json.Unmarshal(raw.Instances, &resource.Instances); err != nil {
+				return nil, err
+			}
+		}
+		resources = append(resources, resource)
+	}
+
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// expectDelim reads the next token and checks it is the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateParser_StreamingMatchesFullDecode(t *testing.T) {
+	path := writeState(t, moduleState)
+
+	full := NewStateParser(path)
+	streaming := NewStateParser(path, WithStreaming())
+
+	fullIDs, err := full.GetInstanceIDs()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	streamIDs, err := streaming.GetInstanceIDs()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(fullIDs) != len(streamIDs) {
+		t.Fatalf("Expected %d IDs, got %d", len(fullIDs), len(streamIDs))
+	}
+
+	for i, id := range fullIDs {
+		if streamIDs[i] != id {
+			t.Errorf("Expected ID %s at %d, got %s", id, i, streamIDs[i])
+		}
+
+		fullAddress, _ := full.GetInstanceAddress(id)
+		streamAddress, _ := streaming.GetInstanceAddress(id)
+		if fullAddress != streamAddress {
+			t.Errorf("Expected address %s, got %s", fullAddress, streamAddress)
+		}
+	}
+}
+
+func TestStateParser_StreamingSkipsOtherResources(t *testing.T) {
+	state := `{
+  "version": 4,
+  "outputs": {"vpc_id": {"value": "vpc-1", "type": "string"}},
+  "resources": [
+    {"mode": "managed", "type": "aws_s3_bucket", "name": "logs", "instances": [{"attributes": {"id": "logs"}}]},
+    {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"id": "i-web"}}]}
+  ],
+  "check_results": null
+}`
+
+	parser := NewStateParser(writeState(t, state), WithStreaming())
+
+	ids, err := parser.GetInstanceIDs()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ids) != 1 || ids[0] != "i-web" {
+		t.Errorf("Expected [i-web], got %v", ids)
+	}
+}
+
+func TestStateParser_StreamingMalformed(t *testing.T) {
+	parser := NewStateParser(writeState(t, `{"version": 4, "resources": [{"type": `), WithStreaming())
+
+	if _, err := parser.GetInstanceIDs(); err == nil {
+		t.Errorf("Expected error for truncated state")
+	}
+}
+
+// writeLargeState writes a state dominated by non-instance resources, which
+// is the shape of a large monolith state
+func writeLargeState(b *testing.B, otherResources, instances int) string {
+	b.Helper()
+
+	attributes := make(map[string]any, 40)
+	for i := 0; i < 40; i++ {
+		attributes[fmt.Sprintf("attribute_%d", i)] = fmt.Sprintf("value-%d-with-some-padding-to-look-realistic", i)
+	}
+
+	resources := make([]Resource, 0, otherResources+instances)
+	for i := 0; i < otherResources; i++ {
+		resources = append(resources, Resource{
+			Mode: "managed", Type: "aws_security_group_rule", Name: fmt.Sprintf("rule_%d", i),
+			Instances: []ResourceInstance{{Attributes: attributes}},
+		})
+	}
+	for i := 0; i < instances; i++ {
+		resources = append(resources, Resource{
+			Mode: "managed", Type: "aws_instance", Name: fmt.Sprintf("web_%d", i),
+			Instances: []ResourceInstance{{Attributes: map[string]any{"id": fmt.Sprintf("i-%d", i), "instance_type": "t3.micro"}}},
+		})
+	}
+
+	data, err := json.Marshal(State{Version: 4, Resources: resources})
+	if err != nil {
+		b.Fatalf("Failed to marshal state: %v", err)
+	}
+
+	path := filepath.Join(b.TempDir(), "terraform.tfstate")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		b.Fatalf("Failed to write state file: %v", err)
+	}
+	return path
+}
+
+func benchmarkStateLoad(b *testing.B, opts ...StateParserOption) {
+	path := writeLargeState(b, 5000, 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		parser := NewStateParser(path, opts...)
+		if _, err := parser.GetInstanceIDs(); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+func BenchmarkStateParser_Load(b *testing.B) {
+	benchmarkStateLoad(b)
+}
+
+func BenchmarkStateParser_LoadStreaming(b *testing.B) {
+	benchmarkStateLoad(b, WithStreaming())
+}
@@ -1,5 +1,7 @@
 package terraform
 
+import "encoding/json"
+
 // State represents the structure of a Terraform state file
 type State struct {
 	Version          int            `json:"version"`
@@ -10,6 +12,7 @@ type State struct {
 
 // Resource represents a resource in Terraform state
 type Resource struct {
+	Module    string             `json:"module,omitempty"`
 	Mode      string             `json:"mode"`
 	Type      string             `json:"type"`
 	Name      string             `json:"name"`
@@ -19,8 +22,92 @@ type Resource struct {
 
 // ResourceInstance represents an instance of a resource
 type ResourceInstance struct {
-	SchemaVersion int            `json:"schema_version"`
-	Attributes    map[string]any `json:"attributes"`
-	Private       string         `json:"private,omitempty"`
-	Dependencies  []string       `json:"dependencies,omitempty"`
+	IndexKey            any             `json:"index_key,omitempty"`
+	SchemaVersion       int             `json:"schema_version"`
+	Attributes          map[string]any  `json:"attributes"`
+	SensitiveAttributes json.RawMessage `json:"sensitive_attributes,omitempty"`
+	Private             string          `json:"private,omitempty"`
+	Dependencies        []string        `json:"dependencies,omitempty"`
+}
+
+// Plan represents the output of `terraform show -json <planfile>`
+type Plan struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	PriorState       *PlanState       `json:"prior_state,omitempty"`
+	ResourceChanges  []ResourceChange `json:"resource_changes,omitempty"`
+}
+
+// PlanState represents a state snapshot embedded in a plan
+type PlanState struct {
+	Values *PlanStateValues `json:"values,omitempty"`
+}
+
+// PlanStateValues holds the root module of a state snapshot
+type PlanStateValues struct {
+	RootModule PlanModule `json:"root_module"`
+}
+
+// PlanModule represents a module and its resources in a plan state snapshot
+type PlanModule struct {
+	Address      string         `json:"address,omitempty"`
+	Resources    []PlanResource `json:"resources,omitempty"`
+	ChildModules []PlanModule   `json:"child_modules,omitempty"`
+}
+
+// PlanResource represents a resource instance in a plan state snapshot
+type PlanResource struct {
+	Address         string         `json:"address"`
+	Mode            string         `json:"mode"`
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	Index           any            `json:"index,omitempty"`
+	Values          map[string]any `json:"values"`
+	SensitiveValues any            `json:"sensitive_values,omitempty"`
+}
+
+// ResourceChange describes the planned change to a resource instance
+type ResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Index   any    `json:"index,omitempty"`
+	Change  Change `json:"change"`
+}
+
+// Change holds the before and after values of a planned change
+type Change struct {
+	Actions         []string       `json:"actions"`
+	Before          map[string]any `json:"before"`
+	After           map[string]any `json:"after"`
+	AfterUnknown    map[string]any `json:"after_unknown,omitempty"`
+	BeforeSensitive any            `json:"before_sensitive,omitempty"`
+	AfterSensitive  any            `json:"after_sensitive,omitempty"`
+}
+
+// StateV3 represents the legacy state layout written by Terraform 0.11 and earlier
+type StateV3 struct {
+	Version          int        `json:"version"`
+	TerraformVersion string     `json:"terraform_version"`
+	Modules          []ModuleV3 `json:"modules"`
+}
+
+// ModuleV3 represents a module in a version 3 state file
+type ModuleV3 struct {
+	Path      []string              `json:"path"`
+	Resources map[string]ResourceV3 `json:"resources"`
+}
+
+// ResourceV3 represents a resource in a version 3 state file
+type ResourceV3 struct {
+	Type     string           `json:"type"`
+	Provider string           `json:"provider"`
+	Primary  *InstanceStateV3 `json:"primary"`
+}
+
+// InstanceStateV3 holds the flattened attributes of a version 3 resource
+type InstanceStateV3 struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
 }